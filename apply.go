@@ -0,0 +1,111 @@
+package bitflags
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SyntaxError is the error of an invalid expression, at the byte offset of the error.
+type SyntaxError struct {
+	Expr   string
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return "bitflags: " + e.Msg + " at column " + strconv.Itoa(e.Offset+1) + " of " + strconv.Quote(e.Expr)
+}
+
+// Apply returns the flags modified by the clauses of the expression, separated by commas
+// and evaluated from left to right, as the chmod command does.
+//
+//	+Aspirin                adds the flags,
+//	-Placebo                removes them,
+//	^Aspirin                toggles them,
+//	=Ibuprofen|Paracetamol  replaces all the flags, or clears them if no name follows.
+//
+// The names are resolved by the lookup function, such as the generated Lookup<T> ones.
+// The flags are returned unchanged with a *SyntaxError if the expression is invalid.
+func Apply[T Bits](flags T, expr string, lookup func(name string) (T, bool)) (T, error) {
+	if strings.TrimSpace(expr) == "" {
+		return flags, &SyntaxError{expr, 0, "empty expression"}
+	}
+
+	v := flags
+
+	for off := 0; off <= len(expr); {
+		end := strings.IndexByte(expr[off:], ',')
+		if end < 0 {
+			end = len(expr)
+		} else {
+			end += off
+		}
+
+		i := skipSpaces(expr, off)
+		if i == end {
+			return flags, &SyntaxError{expr, i, "missing clause"}
+		}
+
+		op := expr[i]
+		if !strings.ContainsRune("+-=^", rune(op)) {
+			return flags, &SyntaxError{expr, i, "expected + - = or ^"}
+		}
+
+		var mask T
+		if names := expr[i+1 : end]; strings.TrimSpace(names) != "" || op != '=' {
+			var err error
+			if mask, err = lookupNames(expr, i+1, end, lookup); err != nil {
+				return flags, err
+			}
+		}
+
+		switch op {
+		case '+':
+			v |= mask
+		case '-':
+			v &^= mask
+		case '^':
+			v ^= mask
+		case '=':
+			v = mask
+		}
+
+		off = end + 1
+	}
+
+	return v, nil
+}
+
+// lookupNames returns the union of the flags named in expr[start:end], separated by "|".
+func lookupNames[T Bits](expr string, start, end int, lookup func(string) (T, bool)) (mask T, err error) {
+	for off := start; off <= end; {
+		next := strings.IndexByte(expr[off:end], '|')
+		if next < 0 {
+			next = end
+		} else {
+			next += off
+		}
+
+		i := skipSpaces(expr, off)
+		name := strings.TrimRight(expr[i:next], " \t")
+		if name == "" {
+			return 0, &SyntaxError{expr, i, "missing flag name"}
+		}
+
+		f, ok := lookup(name)
+		if !ok {
+			return 0, &SyntaxError{expr, i, "unknown flag " + strconv.Quote(name)}
+		}
+		mask |= f
+
+		off = next + 1
+	}
+	return
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
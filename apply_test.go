@@ -0,0 +1,75 @@
+package bitflags
+
+import (
+	"errors"
+	"testing"
+)
+
+type pill uint8
+
+const (
+	placebo pill = 1 << iota
+	aspirin
+	ibuprofen
+	paracetamol
+)
+
+func lookupPill(name string) (pill, bool) {
+	f, ok := map[string]pill{
+		"Placebo":     placebo,
+		"Aspirin":     aspirin,
+		"Ibuprofen":   ibuprofen,
+		"Paracetamol": paracetamol,
+	}[name]
+	return f, ok
+}
+
+func TestApply(t *testing.T) {
+	for _, test := range []struct {
+		flags pill
+		expr  string
+		want  pill
+	}{
+		{placebo, "+Aspirin,-Placebo", aspirin},
+		{placebo, "=Ibuprofen|Paracetamol", ibuprofen | paracetamol},
+		{placebo | aspirin, "^Aspirin,^Ibuprofen", placebo | ibuprofen},
+		{placebo, " + Aspirin | Ibuprofen , -Placebo ", aspirin | ibuprofen},
+		{placebo, "=", 0},
+		{placebo, "=,+Aspirin", aspirin},
+	} {
+		got, err := Apply(test.flags, test.expr, lookupPill)
+		if err != nil {
+			t.Errorf("%q: %v", test.expr, err)
+		} else if got != test.want {
+			t.Errorf("%q: got %b, want %b", test.expr, got, test.want)
+		}
+	}
+}
+
+func TestApplyError(t *testing.T) {
+	for _, test := range []struct {
+		expr   string
+		offset int
+	}{
+		{"", 0},
+		{"Aspirin", 0},
+		{"+Aspirin,", 9},
+		{"+Aspirin,,-Placebo", 9},
+		{"+Aspirin|", 9},
+		{"+Aspirin,-Codeine", 10},
+		{"+Aspirin, -Placebo|Codeine", 19},
+		{"+Aspirin-Placebo", 1},
+	} {
+		got, err := Apply(placebo, test.expr, lookupPill)
+		if got != placebo {
+			t.Errorf("%q: got %b, want the flags unchanged", test.expr, got)
+		}
+
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("%q: expected a syntax error, got %v", test.expr, err)
+		} else if e.Offset != test.offset {
+			t.Errorf("%q: %v, want offset %d", test.expr, err, test.offset)
+		}
+	}
+}
@@ -9,3 +9,15 @@ type Bitflags interface {
 
 	// Contains(f T) bool
 }
+
+// Bits is the constraint of the integer types of the generated flags.
+type Bits interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+// Flags is the constraint of the generated flag types, whose String method prints the names of the flags set.
+type Flags interface {
+	Bits
+	fmt.Stringer
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type cheaderConfig struct {
+	File        string `opts:"help=C header declaring the macros or enums"`
+	Prefix      string `opts:"help=prefix of the macro or enumerator names to import such as FOO_"`
+	Type        string `opts:"help=name of the flag type to declare"`
+	Package     string `opts:"help=package name of the generated file; default the package of the output directory"`
+	Output      string `opts:"help=output file name; default <type>_bitflags.go"`
+	TemplateDir string `opts:"help=directory of *.go.tmpl templates overriding or extending the embedded ones"`
+}
+
+// cheader declares a flag type with the #define macros and enumerators of a C header, and generates its methods.
+func cheader(args []string) {
+	c := cheaderConfig{}
+	opts.New(&c).Name(prog + " cheader").Summary("Declare a flag type with the macros and enumerators of a C header").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	if c.File == "" || c.Type == "" {
+		log.Fatal("-file and -type options are required")
+	}
+
+	genOpts := gen.Options{TemplateDir: c.TemplateDir}
+
+	fs, unevaluated, err := gen.ImportCHeader(c.File, c.Prefix, c.Type, genOpts)
+	for _, u := range unevaluated {
+		log.Print(u)
+	}
+	if err != nil {
+		log.Fatalf("fail to import %s, %v", c.File, err)
+	}
+
+	if c.Output == "" {
+		c.Output = strings.ToLower(fmt.Sprintf("%s_bitflags.go", c.Type))
+	}
+
+	writeFlagSets(c.Package, c.Output, nil, genOpts, fs)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type compatConfig struct {
+	Old   string   `opts:"help=old version of the package: a directory or a git revision"`
+	New   string   `opts:"help=new version of the package directory"`
+	Types []string `opts:"help=list of type names"`
+	Tags  []string `opts:"help=list of build tags to apply"`
+	JSON  bool     `opts:"help=print the report as JSON"`
+}
+
+// compat reports the changes between two versions of the flag types,
+// and exits with a non-zero status if any of them is breaking.
+func compat(args []string) {
+	c := compatConfig{New: "."}
+	opts.New(&c).Name(prog + " compat").Summary("Report the API compatibility between two versions of the flag types").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	if c.Old == "" {
+		log.Fatal("-old option is required")
+	}
+
+	if c.report() {
+		os.Exit(1)
+	}
+}
+
+// report prints the changes and returns whether any of them is breaking.
+func (c *compatConfig) report() (breaking bool) {
+	oldDir := c.Old
+	if info, err := os.Stat(oldDir); err != nil || !info.IsDir() {
+		dir, cleanup, err := checkout(c.New, c.Old)
+		if err != nil {
+			log.Fatalf("fail to checkout %s, %v", c.Old, err)
+		}
+		defer cleanup()
+		oldDir = dir
+	}
+
+	oldGen := loadPackage(oldDir, c.Tags)
+	newGen := loadPackage(c.New, c.Tags)
+
+	var reports []*gen.CompatReport
+
+	for _, typeName := range typeNames(c.Types) {
+		oldValues, err := oldGen.Values(typeName)
+		if err != nil {
+			log.Fatalf("fail to load old %s, %v", typeName, err)
+		}
+		newValues, err := newGen.Values(typeName)
+		if err != nil {
+			log.Fatalf("fail to load new %s, %v", typeName, err)
+		}
+
+		r := gen.Compare(typeName, oldValues, newValues)
+		reports = append(reports, r)
+		breaking = breaking || r.Breaking()
+	}
+
+	if c.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		for _, r := range reports {
+			printCompatReport(r)
+		}
+	}
+
+	return
+}
+
+// loadPackage parses the package in the directory.
+func loadPackage(dir string, tags []string) *gen.Generator {
+	g := gen.New("", false)
+	g.Dir = dir
+
+	if err := g.ParsePackage([]string{"."}, tags); err != nil {
+		log.Fatalf("fail to parse package %s, %v", dir, err)
+	}
+
+	return g
+}
+
+// checkout checks out the git revision to a temporary worktree,
+// and returns the directory corresponding to dir within it.
+func checkout(dir, rev string) (string, func(), error) {
+	root, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", nil, err
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, err
+	}
+	if abs, err = filepath.EvalSymlinks(abs); err != nil {
+		return "", nil, err
+	}
+	rel, err := filepath.Rel(root, abs)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmp, err := os.MkdirTemp("", prog)
+	if err != nil {
+		return "", nil, err
+	}
+	worktree := filepath.Join(tmp, "worktree")
+
+	if _, err = git(root, "worktree", "add", "--detach", worktree, rev); err != nil {
+		os.RemoveAll(tmp)
+		return "", nil, err
+	}
+
+	return filepath.Join(worktree, rel), func() {
+		if _, err := git(root, "worktree", "remove", "--force", worktree); err != nil {
+			log.Print(err)
+		}
+		os.RemoveAll(tmp)
+	}, nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func printCompatReport(r *gen.CompatReport) {
+	fmt.Println(r.Type)
+
+	for _, section := range []struct {
+		title    string
+		breaking bool
+	}{
+		{"Incompatible changes", true},
+		{"Compatible changes", false},
+	} {
+		var changes []gen.Change
+		for _, c := range r.Changes {
+			if c.Kind.Breaking() == section.breaking {
+				changes = append(changes, c)
+			}
+		}
+		if len(changes) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n## %s\n\n", section.title)
+		for _, c := range changes {
+			fmt.Printf("- %s\n", c)
+		}
+	}
+
+	if len(r.Changes) == 0 {
+		fmt.Println("\nNo changes.")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type docConfig struct {
+	Types       []string `opts:"help=list of type names"`
+	Format      string   `opts:"help=table format: md or html"`
+	Readme      string   `opts:"help=file whose <!-- BEGIN bitflags T --> and <!-- END bitflags T --> markers enclose the table"`
+	Output      string   `opts:"help=output file name; default the standard output"`
+	TrimPrefix  string   `opts:"help=trim the 'prefix' from the flag names"`
+	LineComment bool     `opts:"help=use line comment text as flag name when present"`
+	Tags        []string `opts:"help=list of build tags to apply"`
+	Files       []string `opts:"mode=arg,help=package directory or a list of files"`
+}
+
+// doc renders the table of the flags of the types, to the output or between the markers of a readme.
+func doc(args []string) {
+	c := docConfig{Format: gen.DocMarkdown}
+	opts.New(&c).Name(prog + " doc").Summary("Render a table of the flags of the types in Markdown or HTML").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	if len(c.Files) == 0 {
+		c.Files = []string{"."}
+	}
+
+	g := gen.New(c.TrimPrefix, c.LineComment)
+	if err := g.ParsePackage(c.Files, c.Tags); err != nil {
+		log.Fatalf("fail to parse package, %v", err)
+	}
+
+	var readme []byte
+	if c.Readme != "" {
+		var err error
+		if readme, err = os.ReadFile(c.Readme); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+
+	for _, typeName := range typeNames(c.Types) {
+		fs, err := gen.Analyze(g.Package(), typeName)
+		if err != nil {
+			log.Fatalf("fail to analyze %s, %v", typeName, err)
+		}
+
+		var table bytes.Buffer
+		if err = gen.WriteDoc(&table, c.Format, fs); err != nil {
+			log.Fatalf("fail to document %s, %v", typeName, err)
+		}
+
+		if readme == nil {
+			out.Write(table.Bytes())
+		} else if readme, err = gen.ReplaceDoc(readme, typeName, table.Bytes()); err != nil {
+			log.Fatalf("fail to document %s in %s, %v", typeName, c.Readme, err)
+		}
+	}
+
+	switch {
+	case readme != nil:
+		(&config{}).write(c.Readme, readme)
+	case c.Output != "":
+		(&config{}).write(c.Output, out.Bytes())
+	default:
+		(&config{}).write("-", out.Bytes())
+	}
+}
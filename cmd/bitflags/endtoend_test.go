@@ -161,3 +161,85 @@ func runInDir(t testing.TB, dir, name string, arg ...string) error {
 	}
 	return nil
 }
+
+// platformSources declare a constant whose value depends on the GOOS.
+var platformSources = map[string]string{
+	"go.mod": "module platforms\n\ngo 1.18\n",
+	"flag.go": `package main
+
+type Flag uint8
+
+const (
+	Read Flag = 1 << iota
+	Write
+)
+
+func main() {
+	if s := (Read | Extra).String(); s != "Read|Extra" {
+		panic(s)
+	}
+}
+`,
+	"flag_linux.go": `package main
+
+const Extra Flag = 4
+`,
+	"flag_other.go": `//go:build !linux
+
+package main
+
+const Extra Flag = 8
+`,
+}
+
+func TestPlatforms(t *testing.T) {
+	testenv.NeedsTool(t, "go")
+
+	executable := executablePath(t)
+	dir := t.TempDir()
+	for name, src := range platformSources {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := runInDir(t, dir, executable, "-type", "Flag", "--platforms", "linux/amd64,darwin/arm64,linux/arm64", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The linux layout is the most common, and the default.
+	for name, constraint := range map[string]string{
+		"flag_bitflags.go":              "//go:build (linux && amd64) || (linux && arm64)\n",
+		"flag_bitflags_darwin-arm64.go": "//go:build darwin && arm64\n",
+	} {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(src), constraint) {
+			t.Errorf("%s lacks the build constraint %q:\n%s", name, constraint, src)
+		}
+	}
+
+	for _, env := range [][]string{{"GOOS=linux", "GOARCH=amd64"}, {"GOOS=darwin", "GOARCH=arm64"}} {
+		cmd := testenv.Command(t, "go", "vet", ".")
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%v: %v\n%s", env, err, out)
+		}
+	}
+
+	// Neither file is built for the platforms not listed, whose constants may differ.
+	cmd := testenv.Command(t, "go", "list", "-f", "{{.GoFiles}}", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOOS=windows", "GOARCH=amd64")
+	if out, err := cmd.CombinedOutput(); err != nil || strings.Contains(string(out), "flag_bitflags") {
+		t.Errorf("windows/amd64: %v, built %s", err, out)
+	}
+
+	if err = runInDir(t, dir, "go", "run", "."); err != nil {
+		t.Fatal(err)
+	}
+}
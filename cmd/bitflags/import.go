@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type importConfig struct {
+	From        string   `opts:"help=import path of the package declaring the constants such as syscall"`
+	Prefix      string   `opts:"help=prefix of the constant names to import such as MS_"`
+	Prefer      []string `opts:"help=constants kept over the others sharing their value such as MS_RDONLY; default the first declared"`
+	Type        string   `opts:"help=name of the flag type to declare"`
+	Package     string   `opts:"help=package name of the generated file; default the package of the output directory"`
+	Output      string   `opts:"help=output file name; default <type>_bitflags.go"`
+	TemplateDir string   `opts:"help=directory of *.go.tmpl templates overriding or extending the embedded ones"`
+}
+
+// importFlags declares a flag type with the constants of another package, and generates its methods.
+func importFlags(args []string) {
+	c := importConfig{}
+	opts.New(&c).Name(prog + " import").Summary("Declare a flag type with the constants of another package").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	if c.From == "" || c.Type == "" {
+		log.Fatal("-from and -type options are required")
+	}
+
+	genOpts := gen.Options{TemplateDir: c.TemplateDir}
+
+	var prefer []string
+	for _, names := range c.Prefer {
+		prefer = append(prefer, strings.Split(names, ",")...)
+	}
+
+	fs, err := gen.ImportFlags(c.From, c.Prefix, c.Type, prefer, genOpts)
+	if err != nil {
+		log.Fatalf("fail to import %s, %v", c.From, err)
+	}
+	for _, a := range fs.Aliases {
+		log.Printf("%s shares the value of %s, declared as its alias", a.Name, a.Target)
+	}
+
+	if c.Output == "" {
+		c.Output = strings.ToLower(fmt.Sprintf("%s_bitflags.go", c.Type))
+	}
+
+	writeFlagSets(c.Package, c.Output, []string{c.From}, genOpts, fs)
+}
+
+// writeFlagSets generates the declarations and methods of the flag sets to the output file.
+func writeFlagSets(pkgName, output string, imports []string, genOpts gen.Options, sets ...*gen.FlagSet) {
+	if pkgName == "" {
+		pkgName = packageName(filepath.Dir(output))
+	}
+
+	if err := checkDeclared(filepath.Dir(output), filepath.Base(output), sets); err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := gen.GenerateFlagSets(&gen.Package{Name: pkgName, Imports: imports, Options: genOpts}, sets)
+	if err != nil {
+		log.Fatalf("fail to generate, %v", err)
+	}
+
+	(&config{}).write(output, src)
+}
+
+// checkDeclared fails when the types, constants or aliases of the flag sets are declared twice,
+// or already declared by another file of the package in the directory, e.g. imported with another prefix.
+func checkDeclared(dir, output string, sets []*gen.FlagSet) error {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(fi fs.FileInfo) bool {
+		return fi.Name() != output && !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.SkipObjectResolution)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("fail to parse package, %w", err)
+	}
+
+	declared := make(map[string]string) // name => file
+	for _, pkg := range pkgs {
+		for fileName, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				switch decl := decl.(type) {
+				case *ast.FuncDecl:
+					if decl.Recv == nil {
+						declared[decl.Name.Name] = fileName
+					}
+				case *ast.GenDecl:
+					for _, spec := range decl.Specs {
+						switch spec := spec.(type) {
+						case *ast.TypeSpec:
+							declared[spec.Name.Name] = fileName
+						case *ast.ValueSpec:
+							for _, name := range spec.Names {
+								declared[name.Name] = fileName
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	for _, set := range sets {
+		names := []string{set.Type}
+		for _, v := range set.Values {
+			names = append(names, v.OriginalName)
+		}
+		for _, a := range set.Aliases {
+			names = append(names, a.Name)
+		}
+
+		for _, name := range names {
+			if fileName, ok := declared[name]; ok {
+				return fmt.Errorf("%s of type %s is already declared in %s", name, set.Type, fileName)
+			}
+			declared[name] = output
+		}
+	}
+
+	return nil
+}
+
+// packageName returns the name of the package in the directory, or a name derived from the directory.
+func packageName(dir string) string {
+	if pkg, err := build.ImportDir(dir, 0); err == nil {
+		return pkg.Name
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := strings.ToLower(strings.Map(func(r rune) rune {
+		if r == '-' || r == '.' {
+			return '_'
+		}
+		return r
+	}, filepath.Base(abs)))
+	if name == "" || strings.ContainsAny(name[:1], "0123456789") {
+		name = "flags" + name
+	}
+	return name
+}
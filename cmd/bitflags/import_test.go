@@ -0,0 +1,42 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+func TestCheckDeclared(t *testing.T) {
+	dir := t.TempDir()
+	for name, src := range map[string]string{
+		"openflags_bitflags.go":  "package sys\n\ntype OpenFlags uint\n\nconst Async OpenFlags = 0x2000\n",
+		"mountflags_bitflags.go": "package sys\n\ntype MountFlags uint\n\nconst Rdonly MountFlags = 0x1\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	msync := &gen.FlagSet{Type: "MsyncFlags", Values: []gen.Value{{OriginalName: "Async"}, {OriginalName: "Sync"}}}
+	if err := checkDeclared(dir, "msyncflags_bitflags.go", []*gen.FlagSet{msync}); err == nil {
+		t.Error("expected an error for Async declared by OpenFlags")
+	}
+
+	// Regenerating a file doesn't collide with its previous declarations.
+	mount := &gen.FlagSet{Type: "MountFlags", Values: []gen.Value{{OriginalName: "Rdonly"}}, Aliases: []gen.Alias{{Name: "Ro", Target: "Rdonly"}}}
+	if err := checkDeclared(dir, "mountflags_bitflags.go", []*gen.FlagSet{mount}); err != nil {
+		t.Error(err)
+	}
+
+	// Nor does a new package.
+	if err := checkDeclared(filepath.Join(dir, "missing"), "mountflags_bitflags.go", []*gen.FlagSet{mount, msync}); err != nil {
+		t.Error(err)
+	}
+
+	// But the flag sets of a file can't collide with each other.
+	if err := checkDeclared(filepath.Join(dir, "missing"), "flags_bitflags.go", []*gen.FlagSet{mount, mount}); err == nil {
+		t.Error("expected an error for MountFlags declared twice")
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"log"
+	"path/filepath"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type lockConfig struct {
+	Types []string `opts:"help=list of type names"`
+	Tags  []string `opts:"help=list of build tags to apply"`
+	Allow bool     `opts:"help=update the lock file even when the constants conflict with it"`
+	Files []string `opts:"mode=arg,help=package directory or a list of files"`
+}
+
+// lock writes the <type>.bitflags.lock file recording the name to bit assignments of each type.
+func lock(args []string) {
+	c := lockConfig{}
+	opts.New(&c).Name(prog + " lock").Summary("Record the name to bit assignments in <type>.bitflags.lock").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	g := gen.New("", false)
+
+	if len(c.Files) == 0 {
+		c.Files = []string{"."}
+	}
+
+	if err := g.ParsePackage(c.Files, c.Tags); err != nil {
+		log.Fatalf("fail to parse package, %v", err)
+	}
+
+	for _, typeName := range typeNames(c.Types) {
+		l := checkLock(g, packageDir(c.Files), typeName, c.Allow)
+		if l == nil {
+			l = &gen.Lock{Type: typeName}
+		}
+
+		values, err := g.Values(typeName)
+		if err != nil {
+			log.Fatalf("fail to lock %s, %v", typeName, err)
+		}
+
+		l.Update(values)
+
+		if err = l.WriteFile(filepath.Join(packageDir(c.Files), gen.LockFileName(typeName))); err != nil {
+			log.Fatalf("writing lock file: %s", err)
+		}
+	}
+}
+
+// checkLock checks the constants of the type against its lock file, if any.
+//
+// Conflicts are fatal unless allowed, in which case they are only logged.
+func checkLock(g *gen.Generator, dir, typeName string, allow bool) *gen.Lock {
+	l, err := gen.ReadLock(filepath.Join(dir, gen.LockFileName(typeName)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		log.Fatal(err)
+	}
+
+	values, err := g.Values(typeName)
+	if err != nil {
+		log.Fatalf("fail to check %s, %v", typeName, err)
+	}
+
+	violations := l.Check(values)
+	for _, v := range violations {
+		log.Printf("%s: %v", typeName, v)
+	}
+	if len(violations) > 0 && !allow {
+		log.Fatalf("%s: %d conflicts with %s, use --allow to override", typeName, len(violations), gen.LockFileName(typeName))
+	}
+
+	return l
+}
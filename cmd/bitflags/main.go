@@ -45,6 +45,12 @@
 //	func (Pill) Paracetamol() bool
 //	func (Pill) Acetaminophen() bool
 //
+//	// The doc, or trailing, comment of a single flag
+//	func (Pill) Description() string
+//
+//	// The names of the flags paired with their descriptions, for usage output
+//	func PillHelp() [][2]string
+//
 // That method will translate the value of a Pill constant to the string representation
 // of the respective constant name, so that the call fmt.Print(painkiller.Aspirin|painkiller.Paracetamol) will
 // print the string "Aspirin|Paracetamol".
@@ -72,6 +78,82 @@
 //	PillAspirin // Aspirin
 //
 // to suppress it in the output.
+//
+// The constants with a "Deprecated: use X" comment are printed and parsed as their
+// replacement X, calling the PillDeprecationHook if set, and IsDeprecated returns the
+// deprecation notice of a single flag.
+//
+// The //bitflags:attr key=value directives of the constants generate the Attr method,
+// returning the value of an attribute of a single flag, and the PillWithAttr function,
+// returning the mask of the flags with the attribute set to the value.
+//
+// The //bitflags:exclusive A,B,C and //bitflags:requires A=>B directives of the type
+// generate the Validate method, returning a *PillRuleError listing the violated rules,
+// and the Normalize method, adding the required flags. Contradictory rules fail the
+// generation.
+//
+// The //bitflags:field Priority bits=4..6 type=uint8 directives of the type pack small
+// integer fields among the flags, with a Priority getter and a SetPriority setter checking
+// for overflows. String prints the fields as Priority=5, and ParsePill, generated with
+// -json, parses them.
+//
+// The -apply flag generates the Apply method, modifying the flags by a chmod-like expression
+// such as "+Aspirin,-Placebo", "^Aspirin" or "=Ibuprofen|Paracetamol", parsed by the
+// github.com/flier/go-bitflags package with the generated LookupPill function.
+//
+// The -mode=enum flag generates the methods of plain enumerations instead, as the stringer
+// command does: String printing the name of a single value, ParsePill, PillValues, IsValid,
+// and the text marshalers, which encoding/json uses unless -json is set. A //bitflags:mode
+// directive selects the mode of a single type, so that flags and enums share a run.
+//
+// The lock subcommand writes a <type>.bitflags.lock file recording each name to bit
+// assignment. Once it exists, generation fails when a locked name changes value, a
+// locked bit is given to a different name, or a retired bit is reused without a
+// //bitflags:retired marker. The -allow flag turns these failures into warnings.
+//
+//	bitflags lock -type=Pill
+//
+// The compat subcommand reports the changes between two versions of the flag types,
+// where the old version is a directory or a git revision, and exits with a non-zero
+// status when any of them is breaking.
+//
+//	bitflags compat -old v1.2.0 -new . -type=Pill
+//
+// The import subcommand declares a flag type whose constants alias those of another
+// package with the given prefix, and generates its methods.
+//
+//	bitflags import -from syscall -prefix MS_ -type MountFlags
+//
+// The spec subcommand declares the flag types of a JSON or YAML spec file, validating
+// that names and values are unique and fit the underlying type, and generates their methods.
+//
+//	bitflags spec pill.yaml
+//
+// The cheader subcommand declares a flag type with the #define macros and enumerators
+// of a C header with the given prefix, evaluating integer, hex and shift expressions
+// without a C compiler, and reports the macros it couldn't evaluate.
+//
+//	bitflags cheader -file foo.h -prefix FOO_ -type FooFlags
+//
+// The doc subcommand renders a Markdown, or HTML, table of the name, bit positions,
+// hexadecimal value, aliases, deprecation notice and doc comment of each flag. With
+// -readme, it replaces the text between the <!-- BEGIN bitflags Pill --> and
+// <!-- END bitflags Pill --> comments of the file, so that go generate keeps it current.
+//
+//	bitflags doc -type Pill -format md -readme README.md
+//
+// The -lang flag selects the output language. With -lang=ts, bitflags writes a TypeScript
+// module pill_bitflags.ts holding a frozen object of the values, and the hasPill, formatPill
+// and parsePill functions matching the Go Contains and String methods. With -lang=python,
+// it writes an enum.IntFlag subclass, and with -lang=rust, a bitflags! macro invocation,
+// both named and printed like the Go constants, with their aliases and doc comments.
+//
+// The -json flag generates the Parse<T> function, and the MarshalJSON and UnmarshalJSON
+// methods encoding the flags as an array of names with -json=array, or as the String
+// method formats them with -json=string; -json=int keeps the default integer encoding.
+// With -lang=jsonschema or -lang=openapi, bitflags writes the JSON Schema definitions,
+// or the OpenAPI 3 component schemas, of the same JSON shape, with the bit table of the
+// flags and their doc comments in x-flags.
 package main
 
 import (
@@ -93,9 +175,26 @@ type config struct {
 	TrimPrefix  string   `opts:"help=trim the 'prefix' from the generated constant names"`
 	LineComment bool     `opts:"help=use line comment text as printed text when present"`
 	Tags        []string `opts:"help=list of build tags to apply"`
+	Allow       bool     `opts:"help=warn instead of failing when the constants conflict with the lock file"`
+	TemplateDir string   `opts:"help=directory of *.go.tmpl templates overriding or extending the embedded ones"`
+	Lang        string   `opts:"help=output language: go ts python rust jsonschema or openapi"`
+	JSON        string   `opts:"help=JSON shape of the flags: array string or int"`
+	Apply       bool     `opts:"help=generate the Apply method using the github.com/flier/go-bitflags package"`
+	Mode        string   `opts:"help=kind of the types: flags or enum; overridden by a //bitflags:mode directive"`
+	Platforms   []string `opts:"name=platforms,help=comma-separated list of GOOS/GOARCH platforms; generates one file per distinct constant layout"`
 	Files       []string `opts:"mode=arg,help=package directory or a list of files"`
 }
 
+// commands are the subcommands selected by the first argument.
+var commands = map[string]func(args []string){
+	"lock":    lock,
+	"compat":  compat,
+	"import":  importFlags,
+	"spec":    spec,
+	"cheader": cheader,
+	"doc":     doc,
+}
+
 const (
 	prog   = "bitflags"
 	author = "Flier Lu <flier.lu@gmail.com>"
@@ -103,59 +202,103 @@ const (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[1:])
+			return
+		}
+	}
+
 	c := config{}
 	opts.New(&c).Summary(summary()).Repo(repo).Author(author).Parse()
 
-	g := gen.New(c.TrimPrefix, c.LineComment)
-
 	if len(c.Files) == 0 {
 		c.Files = []string{"."}
 	}
 
+	if _, ok := gen.Languages[c.Lang]; !ok && c.Lang != "" {
+		log.Fatalf("unknown output language %s", c.Lang)
+	}
+
+	if len(c.Platforms) > 0 {
+		if c.Lang != "" && c.Lang != "go" {
+			log.Fatal("-platforms option applies only to the go output language")
+		}
+		c.generatePlatforms()
+		return
+	}
+
+	g := c.load(nil)
+
+	c.write(c.outputName(), c.generate(g))
+}
+
+// load parses the package within the extra environment, and checks the types against their lock files.
+func (c *config) load(env []string) *gen.Generator {
+	g := gen.New(c.TrimPrefix, c.LineComment)
+	g.TemplateDir = c.TemplateDir
+	g.Lang = c.Lang
+	g.JSON = c.JSON
+	g.Mode = c.Mode
+	g.Apply = c.Apply
+	g.Env = env
+
 	if err := g.ParsePackage(c.Files, c.Tags); err != nil {
 		log.Fatalf("fail to parse package, %v", err)
 	}
 
+	for _, typeName := range typeNames(c.Types) {
+		checkLock(g, packageDir(c.Files), typeName, c.Allow)
+	}
+
+	return g
+}
+
+// generate produces the formatted source of the types.
+func (c *config) generate(g *gen.Generator) []byte {
 	if err := g.GenerateHeader(); err != nil {
 		log.Fatalf("fail to generate header, %v", err)
 	}
 
 	// Run generate for each type.
-	for _, s := range c.Types {
-		for _, typeName := range strings.Split(s, ",") {
-			if err := g.Generate(typeName); err != nil {
-				log.Fatalf("fail to generate %s, %v", typeName, err)
-			}
+	for _, typeName := range typeNames(c.Types) {
+		if err := g.Generate(typeName); err != nil {
+			log.Fatalf("fail to generate %s, %v", typeName, err)
 		}
 	}
 
-	src := g.Format()
+	return g.Format()
+}
+
+// outputName returns the name of the output file, or "-" for the standard output.
+func (c *config) outputName() string {
+	if c.Output != "" {
+		return c.Output
+	}
+
+	if len(c.Tags) != 0 && !(len(c.Files) == 1 && isDirectory(c.Files[0])) {
+		log.Fatal("-tags option applies only to directories, not when files are specified")
+	}
+
+	baseName := strings.ToLower(fmt.Sprintf("%s_bitflags%s", c.Types[0], ext(c.Lang)))
+	return filepath.Join(packageDir(c.Files), baseName)
+}
 
-	// Write to file.
-	if c.Output == "-" {
+// write writes the source to the named file, or to the standard output for "-".
+func (c *config) write(name string, src []byte) {
+	if name == "-" {
 		fmt.Print(string(src))
-	} else {
-		outputName := c.Output
-		if outputName == "" {
-			var dir string
-
-			if len(c.Files) == 1 && isDirectory(c.Files[0]) {
-				dir = c.Files[0]
-			} else {
-				if len(c.Tags) != 0 {
-					log.Fatal("-tags option applies only to directories, not when files are specified")
-				}
-				dir = filepath.Dir(c.Files[0])
-			}
-
-			baseName := strings.ToLower(fmt.Sprintf("%s_bitflags.go", c.Types[0]))
-			outputName = filepath.Join(dir, baseName)
-		}
+	} else if err := os.WriteFile(name, src, 0644); err != nil {
+		log.Fatalf("writing output: %s", err)
+	}
+}
 
-		if err := os.WriteFile(outputName, src, 0644); err != nil {
-			log.Fatalf("writing output: %s", err)
-		}
+// ext returns the extension of the files of the output language.
+func ext(lang string) string {
+	if lang == "" {
+		return ".go"
 	}
+	return gen.Languages[lang]
 }
 
 func summary() string {
@@ -169,6 +312,22 @@ func summary() string {
 	return strings.Trim(b.String(), "\n")
 }
 
+// typeNames splits the comma-separated lists of type names.
+func typeNames(types []string) (names []string) {
+	for _, s := range types {
+		names = append(names, strings.Split(s, ",")...)
+	}
+	return
+}
+
+// packageDir returns the directory of the package named by the files.
+func packageDir(files []string) string {
+	if len(files) == 1 && isDirectory(files[0]) {
+		return files[0]
+	}
+	return filepath.Dir(files[0])
+}
+
 func isDirectory(name string) bool {
 	info, err := os.Stat(name)
 	if err != nil {
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+// platform is a GOOS/GOARCH pair.
+type platform struct {
+	goos, goarch string
+}
+
+func parsePlatform(s string) platform {
+	goos, goarch, ok := strings.Cut(s, "/")
+	if !ok || goos == "" || goarch == "" {
+		log.Fatalf("invalid platform %q, expected GOOS/GOARCH", s)
+	}
+	return platform{goos, goarch}
+}
+
+func (p platform) String() string { return p.goos + "/" + p.goarch }
+
+// constraint returns the build constraint expression matching the platform.
+func (p platform) constraint() string { return p.goos + " && " + p.goarch }
+
+// layout groups the platforms sharing the same constants.
+type layout struct {
+	key       string
+	platforms []platform
+	g         *gen.Generator // Generator of the first platform.
+}
+
+// constraint returns the build constraint expression matching the platforms of the layout.
+func (l *layout) constraint() string {
+	if len(l.platforms) == 1 {
+		return l.platforms[0].constraint()
+	}
+
+	terms := make([]string, len(l.platforms))
+	for i, p := range l.platforms {
+		terms[i] = "(" + p.constraint() + ")"
+	}
+	return strings.Join(terms, " || ")
+}
+
+// generatePlatforms loads the package once per platform, and generates one file per distinct constant layout.
+//
+// The most common layout is generated as the default file, built for its own platforms only, since the constants
+// may not exist on the others; when all the platforms share the same layout, it is the only, unconstrained, file.
+func (c *config) generatePlatforms() {
+	var layouts []*layout
+
+	for _, s := range strings.Split(strings.Join(c.Platforms, ","), ",") {
+		p := parsePlatform(s)
+		g := c.load([]string{"GOOS=" + p.goos, "GOARCH=" + p.goarch})
+		key := c.layoutKey(g)
+
+		var l *layout
+		for _, existing := range layouts {
+			if existing.key == key {
+				l = existing
+				break
+			}
+		}
+		if l == nil {
+			l = &layout{key: key, g: g}
+			layouts = append(layouts, l)
+		}
+		l.platforms = append(l.platforms, p)
+	}
+
+	def := layouts[0]
+	for _, l := range layouts[1:] {
+		if len(l.platforms) > len(def.platforms) {
+			def = l
+		}
+	}
+
+	outputName := c.outputName()
+	if outputName == "-" {
+		log.Fatal("-platforms option can't write to the standard output")
+	}
+
+	for _, l := range layouts {
+		if l == def {
+			continue
+		}
+
+		names := make([]string, len(l.platforms))
+		for i, p := range l.platforms {
+			names[i] = p.goos + "-" + p.goarch
+		}
+
+		l.g.BuildConstraint = l.constraint()
+		c.write(fmt.Sprintf("%s_%s.go", strings.TrimSuffix(outputName, filepath.Ext(outputName)), strings.Join(names, "_")), c.generate(l.g))
+	}
+
+	if len(layouts) > 1 {
+		def.g.BuildConstraint = def.constraint()
+	}
+	c.write(outputName, c.generate(def.g))
+}
+
+// layoutKey describes the constants of the types loaded by the generator.
+func (c *config) layoutKey(g *gen.Generator) string {
+	var b strings.Builder
+
+	for _, typeName := range typeNames(c.Types) {
+		values, err := g.Values(typeName)
+		if err != nil {
+			log.Fatalf("fail to load %s, %v", typeName, err)
+		}
+
+		b.WriteString(typeName)
+		for _, v := range values {
+			fmt.Fprintf(&b, " %s=%s", v.OriginalName, v.Str)
+		}
+		b.WriteByte('\n')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/jpillora/opts"
+
+	"github.com/flier/go-bitflags/pkg/gen"
+)
+
+type specConfig struct {
+	Package     string `opts:"help=package name of the generated file; default the spec package or that of the output directory"`
+	Output      string `opts:"help=output file name; default <type>_bitflags.go"`
+	Lang        string `opts:"help=output language: go ts python rust jsonschema or openapi"`
+	JSON        string `opts:"help=JSON shape of the flags: array string or int"`
+	TemplateDir string `opts:"help=directory of *.go.tmpl templates overriding or extending the embedded ones"`
+	File        string `opts:"mode=arg,help=spec file in JSON or YAML"`
+}
+
+// spec declares the flag types of a spec file, and generates their methods.
+func spec(args []string) {
+	c := specConfig{}
+	opts.New(&c).Name(prog + " spec").Summary("Declare the flag types of a JSON or YAML spec file").
+		Repo(repo).Author(author).ParseArgs(args)
+
+	s, err := gen.ReadSpec(c.File)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	genOpts := gen.Options{TemplateDir: c.TemplateDir, Lang: c.Lang, JSON: c.JSON}
+
+	sets, err := s.FlagSets(genOpts)
+	if err != nil {
+		log.Fatalf("invalid spec %s, %v", c.File, err)
+	}
+	if len(sets) == 0 {
+		log.Fatalf("no types defined in %s", c.File)
+	}
+
+	if c.Package == "" {
+		c.Package = s.Package
+	}
+	if c.Output == "" {
+		c.Output = strings.ToLower(fmt.Sprintf("%s_bitflags%s", sets[0].Type, ext(c.Lang)))
+	}
+
+	writeFlagSets(c.Package, c.Output, nil, genOpts, sets...)
+}
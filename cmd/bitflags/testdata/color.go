@@ -0,0 +1,31 @@
+package main
+
+import "fmt"
+
+//bitflags:mode enum
+type Color uint8
+
+const (
+	Red Color = iota + 1
+	Green
+	Blue
+	Purple Color = 10
+)
+
+func main() {
+	ck(Red, "Red")
+	ck(Blue, "Blue")
+	ck(Purple, "Purple")
+	ck(0, "Color(0)")
+	ck(4, "Color(4)")
+	ck(11, "Color(11)")
+	if _, err := Color(0).MarshalText(); err == nil {
+		panic("color.go: expected an error marshaling Color(0)")
+	}
+}
+
+func ck(color Color, expected string) {
+	if got := fmt.Sprint(color); got != expected {
+		panic("color.go: \n\tgot: " + got + "\n\texpected:" + expected)
+	}
+}
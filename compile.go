@@ -0,0 +1,340 @@
+package bitflags
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Term is a conjunction of a Predicate, matching the flags with all the Required bits set
+// and all the Forbidden bits cleared.
+type Term[T Bits] struct {
+	Required, Forbidden T
+}
+
+// Match reports whether the flags match the term.
+func (t Term[T]) Match(flags T) bool {
+	return flags&t.Required == t.Required && flags&t.Forbidden == 0
+}
+
+// Predicate is a compiled filter expression, in its disjunctive normal form:
+// it matches the flags matching any of its terms.
+type Predicate[T Bits] struct {
+	terms []Term[T]
+}
+
+// Match reports whether the flags match the predicate, without allocation.
+func (p Predicate[T]) Match(flags T) bool {
+	for _, t := range p.terms {
+		if flags&t.Required == t.Required && flags&t.Forbidden == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Terms returns the terms of the predicate; none for a predicate which never matches,
+// and an empty one for a predicate which always matches.
+func (p Predicate[T]) Terms() []Term[T] {
+	return append([]Term[T](nil), p.terms...)
+}
+
+// maxTerms limits the size of the normal form of the expressions.
+const maxTerms = 1024
+
+// Compile parses a boolean filter expression of flag names, and simplifies it into a predicate
+// of mask checks. The operators are, by decreasing precedence,
+//
+//	!A, not A        the flag A isn't set,
+//	A & B, A and B   both flags are set,
+//	A ^ B, A xor B   a single one of the flags is set,
+//	A | B, A or B    any of the flags is set,
+//
+// with parentheses for grouping, e.g. "Aspirin & !Placebo | (Ibuprofen & Paracetamol)".
+// A flag of several bits is set if all its bits are set.
+//
+// The names are resolved by the lookup function, such as the generated Lookup<T> ones.
+func Compile[T Bits](expr string, lookup func(name string) (T, bool)) (Predicate[T], error) {
+	p := &parser[T]{expr: expr, lookup: lookup}
+	p.next()
+
+	terms, err := p.or()
+	if err == nil && p.tok != tokEOF {
+		err = p.errorf("unexpected " + p.describe())
+	}
+	if err != nil {
+		return Predicate[T]{}, err
+	}
+
+	return Predicate[T]{terms}, nil
+}
+
+type token int
+
+const (
+	tokEOF token = iota
+	tokName
+	tokAnd
+	tokOr
+	tokXor
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+// keywords are the operators spelled as words.
+var keywords = map[string]token{"and": tokAnd, "or": tokOr, "xor": tokXor, "not": tokNot}
+
+// parser is a recursive descent parser of the filter expressions,
+// producing the disjunctive normal form of each subexpression.
+type parser[T Bits] struct {
+	expr   string
+	lookup func(string) (T, bool)
+	off    int    // The offset of the current token.
+	end    int    // The offset following the current token.
+	tok    token  // The current token.
+	name   string // The name of a tokName token.
+}
+
+func (p *parser[T]) errorf(msg string) error {
+	return &SyntaxError{p.expr, p.off, msg}
+}
+
+func (p *parser[T]) describe() string {
+	if p.tok == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(p.expr[p.off:p.end])
+}
+
+// next scans the next token.
+func (p *parser[T]) next() {
+	p.off = skipSpaces(p.expr, p.end)
+	p.end = p.off
+	if p.off == len(p.expr) {
+		p.tok = tokEOF
+		return
+	}
+
+	p.end++
+	switch c := p.expr[p.off]; c {
+	case '&', '|':
+		p.tok = tokAnd
+		if c == '|' {
+			p.tok = tokOr
+		}
+		if p.end < len(p.expr) && p.expr[p.end] == c { // && and ||
+			p.end++
+		}
+	case '^':
+		p.tok = tokXor
+	case '!':
+		p.tok = tokNot
+	case '(':
+		p.tok = tokLParen
+	case ')':
+		p.tok = tokRParen
+	default:
+		for p.end < len(p.expr) && !strings.ContainsRune("&|^!() \t", rune(p.expr[p.end])) {
+			p.end++
+		}
+		p.name = p.expr[p.off:p.end]
+		p.tok = tokName
+		if tok, ok := keywords[p.name]; ok {
+			p.tok = tok
+		}
+	}
+}
+
+func (p *parser[T]) or() (dnf[T], error) {
+	return p.binary(tokOr, p.xor, dnf[T].or)
+}
+
+func (p *parser[T]) xor() (dnf[T], error) {
+	return p.binary(tokXor, p.and, dnf[T].xor)
+}
+
+func (p *parser[T]) and() (dnf[T], error) {
+	return p.binary(tokAnd, p.unary, dnf[T].and)
+}
+
+// binary parses the left-associative operator, combining the operands.
+func (p *parser[T]) binary(op token, operand func() (dnf[T], error), combine func(x, y dnf[T]) dnf[T]) (dnf[T], error) {
+	x, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok == op {
+		off := p.off
+		p.next()
+
+		y, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		if x = combine(x, y); len(x) > maxTerms {
+			return nil, &SyntaxError{p.expr, off, "expression too complex"}
+		}
+	}
+
+	return x, nil
+}
+
+func (p *parser[T]) unary() (dnf[T], error) {
+	switch p.tok {
+	case tokNot:
+		off := p.off
+		p.next()
+
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if x = x.not(); len(x) > maxTerms {
+			return nil, &SyntaxError{p.expr, off, "expression too complex"}
+		}
+		return x, nil
+
+	case tokLParen:
+		open := p.off
+		p.next()
+
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != tokRParen {
+			if p.tok == tokEOF {
+				return nil, &SyntaxError{p.expr, open, "unclosed parenthesis"}
+			}
+			return nil, p.errorf("expected ) instead of " + p.describe())
+		}
+		p.next()
+		return x, nil
+
+	case tokName:
+		f, ok := p.lookup(p.name)
+		if !ok {
+			return nil, p.errorf("unknown flag " + strconv.Quote(p.name))
+		}
+		p.next()
+		return dnf[T]{{Required: f}}, nil
+
+	default:
+		return nil, p.errorf("expected a flag name instead of " + p.describe())
+	}
+}
+
+// dnf is a disjunction of terms, kept simplified: without contradictory terms,
+// nor terms implied by others.
+type dnf[T Bits] []Term[T]
+
+// The operations return the terms unsimplified once they exceed maxTerms, for the callers to fail on their size,
+// rather than simplifying ever larger products.
+
+func (x dnf[T]) or(y dnf[T]) dnf[T] {
+	z := append(append(dnf[T](nil), x...), y...)
+	if len(z) > maxTerms {
+		return z
+	}
+	return z.simplify()
+}
+
+func (x dnf[T]) and(y dnf[T]) dnf[T] {
+	var z dnf[T]
+	for _, a := range x {
+		for _, b := range y {
+			t := Term[T]{a.Required | b.Required, a.Forbidden | b.Forbidden}
+			if t.Required&t.Forbidden == 0 {
+				if z = append(z, t); len(z) > maxTerms {
+					return z
+				}
+			}
+		}
+	}
+	return z.simplify()
+}
+
+func (x dnf[T]) xor(y dnf[T]) dnf[T] {
+	return x.and(y.not()).or(x.not().and(y))
+}
+
+// not applies the De Morgan's laws: the negation of a term is the disjunction of
+// the negations of its bits, and the negation of a disjunction the conjunction
+// of the negations of its terms.
+func (x dnf[T]) not() dnf[T] {
+	z := dnf[T]{{}} // Always true.
+	for _, t := range x {
+		var neg dnf[T]
+		for _, bit := range singleBits(t.Required) {
+			neg = append(neg, Term[T]{Forbidden: bit})
+		}
+		for _, bit := range singleBits(t.Forbidden) {
+			neg = append(neg, Term[T]{Required: bit})
+		}
+		if z = z.and(neg); len(z) == 0 || len(z) > maxTerms {
+			break // Either always false, or too complex.
+		}
+	}
+	return z
+}
+
+// simplify merges the terms differing by a single bit, required by one and forbidden by the other,
+// and removes the duplicated terms and those implied by more general ones.
+func (x dnf[T]) simplify() dnf[T] {
+	for merged := true; merged; {
+		merged = false
+
+		terms := make(map[Term[T]]bool, len(x)) // term => merged
+		for _, t := range x {
+			terms[t] = false
+		}
+
+		var z dnf[T]
+		for _, t := range x {
+			// The term requiring the bit d merges with the one forbidding it instead.
+			for _, d := range singleBits(t.Required) {
+				u := Term[T]{t.Required &^ d, t.Forbidden | d}
+				if _, ok := terms[u]; ok {
+					z = append(z, Term[T]{t.Required &^ d, t.Forbidden})
+					terms[t], terms[u] = true, true
+					merged = true
+				}
+			}
+		}
+		for _, t := range x {
+			if !terms[t] {
+				z = append(z, t)
+				terms[t] = true // Once.
+			}
+		}
+		x = z
+	}
+
+	z := x[:0]
+	for i, t := range x {
+		implied := false
+		for j, u := range x {
+			if i != j && u.Required&t.Required == u.Required && u.Forbidden&t.Forbidden == u.Forbidden &&
+				(u != t || j < i) {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			z = append(z, t)
+		}
+	}
+	return z
+}
+
+// singleBits returns the single bits of the mask.
+func singleBits[T Bits](mask T) (bits []T) {
+	for bit := T(1); bit != 0; bit <<= 1 {
+		if mask&bit != 0 {
+			bits = append(bits, bit)
+		}
+	}
+	return
+}
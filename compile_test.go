@@ -0,0 +1,138 @@
+package bitflags
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompile(t *testing.T) {
+	const both = aspirin | ibuprofen
+
+	lookup := func(name string) (pill, bool) {
+		if name == "Both" {
+			return both, true
+		}
+		return lookupPill(name)
+	}
+
+	for _, test := range []struct {
+		expr  string
+		terms []Term[pill]
+	}{
+		{"Aspirin", []Term[pill]{{aspirin, 0}}},
+		{"!Placebo", []Term[pill]{{0, placebo}}},
+		{"Aspirin & !Placebo | (Ibuprofen & Paracetamol)", []Term[pill]{{aspirin, placebo}, {ibuprofen | paracetamol, 0}}},
+		{"Aspirin and not Placebo or Ibuprofen && Paracetamol", []Term[pill]{{aspirin, placebo}, {ibuprofen | paracetamol, 0}}},
+		{"Aspirin ^ Placebo", []Term[pill]{{aspirin, placebo}, {placebo, aspirin}}},
+		{"Aspirin & Placebo | Aspirin & !Placebo", []Term[pill]{{aspirin, 0}}},
+		{"Aspirin | Aspirin & Placebo", []Term[pill]{{aspirin, 0}}},
+		{"Aspirin & !Aspirin", nil},
+		{"Aspirin | !Aspirin", []Term[pill]{{}}},
+		{"!Both", []Term[pill]{{0, aspirin}, {0, ibuprofen}}},
+		{"!(Aspirin | Placebo) & Ibuprofen", []Term[pill]{{ibuprofen, placebo | aspirin}}},
+	} {
+		p, err := Compile(test.expr, lookup)
+		if err != nil {
+			t.Errorf("%q: %v", test.expr, err)
+			continue
+		}
+		if terms := p.Terms(); !reflect.DeepEqual(terms, test.terms) {
+			t.Errorf("%q: got terms %v, want %v", test.expr, terms, test.terms)
+		}
+	}
+}
+
+func TestCompileMatch(t *testing.T) {
+	exprs := []struct {
+		expr string
+		eval func(a, b, c, d bool) bool
+	}{
+		{"Aspirin & !Placebo | (Ibuprofen & Paracetamol)", func(a, b, c, d bool) bool { return b && !a || c && d }},
+		{"(Placebo ^ Aspirin) ^ !(Ibuprofen | Paracetamol)", func(a, b, c, d bool) bool { return (a != b) != !(c || d) }},
+		{"!(Placebo & Aspirin ^ Ibuprofen) | Paracetamol & Placebo", func(a, b, c, d bool) bool { return !((a && b) != c) || d && a }},
+	}
+
+	for _, test := range exprs {
+		p, err := Compile(test.expr, lookupPill)
+		if err != nil {
+			t.Fatalf("%q: %v", test.expr, err)
+		}
+
+		for v := pill(0); v < 16; v++ {
+			want := test.eval(v&placebo != 0, v&aspirin != 0, v&ibuprofen != 0, v&paracetamol != 0)
+			if got := p.Match(v); got != want {
+				t.Errorf("%q: %04b got %v, want %v", test.expr, v, got, want)
+			}
+		}
+	}
+}
+
+func TestCompileAllocs(t *testing.T) {
+	p, err := Compile("Aspirin & !Placebo | (Ibuprofen & Paracetamol)", lookupPill)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := testing.AllocsPerRun(100, func() { p.Match(ibuprofen | paracetamol) }); n != 0 {
+		t.Errorf("got %v allocations per match", n)
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	for _, test := range []struct {
+		expr   string
+		offset int
+	}{
+		{"", 0},
+		{"Aspirin &", 9},
+		{"Aspirin Placebo", 8},
+		{"(Aspirin | Placebo", 0},
+		{"Aspirin)", 7},
+		{"Aspirin & !Codeine", 11},
+		{"!", 1},
+	} {
+		_, err := Compile(test.expr, lookupPill)
+
+		var e *SyntaxError
+		if !errors.As(err, &e) {
+			t.Errorf("%q: expected a syntax error, got %v", test.expr, err)
+		} else if e.Offset != test.offset {
+			t.Errorf("%q: %v, want offset %d", test.expr, err, test.offset)
+		}
+	}
+}
+
+func TestCompileTooComplex(t *testing.T) {
+	lookup := func(name string) (uint64, bool) {
+		n, err := strconv.Atoi(strings.TrimPrefix(name, "F"))
+		return 1 << n, err == nil && n < 64
+	}
+
+	// The normal form of the negation of n conjunctions of pairs has 2^n terms.
+	var pairs []string
+	for i := 0; i < 32; i += 2 {
+		pairs = append(pairs, fmt.Sprintf("F%d&F%d", i, i+1))
+	}
+
+	for _, expr := range []string{
+		"!(" + strings.Join(pairs, "|") + ")",
+		"F62 ^ !(" + strings.Join(pairs, "|") + ")",
+		"(" + strings.Join(pairs, "|") + ") ^ (" + strings.Join(pairs[1:], "|") + ")",
+	} {
+		start := time.Now()
+		_, err := Compile(expr, lookup)
+
+		var e *SyntaxError
+		if !errors.As(err, &e) || e.Msg != "expression too complex" {
+			t.Errorf("%q: expected a too complex expression, got %v", expr, err)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("%q: compiled in %v", expr, d)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package bitflags
+
+import (
+	"encoding/json"
+	"expvar"
+	"math/bits"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Counter counts the occurrences of each flag in the observed values.
+//
+// It is safe for concurrent use, and implements expvar.Var, rendering the counts
+// as a JSON object keyed by the flag names.
+type Counter[T Flags] struct {
+	counts   [64]uint64 // First, to be 64-bit aligned for the atomic operations.
+	mask     uint64     // The bits of T.
+	declared uint64     // The bits named by the String method.
+	names    [64]string
+}
+
+// NewCounter returns a counter of the flags of T, named by their String method,
+// or by their hexadecimal value for the undeclared ones, see flagNames.
+func NewCounter[T Flags]() *Counter[T] {
+	c := &Counter[T]{}
+	c.names, c.declared = flagNames[T]()
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		c.mask |= 1 << i
+	}
+	return c
+}
+
+// flagNames returns the names of the bits of T, as printed by the String method of each bit,
+// and the mask of the bits so named.
+//
+// The other bits are named by their hexadecimal value, e.g. "0x80", so that each name counts
+// a single bit: the undeclared bits, those of the fields, printed as "Priority=4", and those
+// printed as the name of another bit, such as the deprecated flags printed as their replacement,
+// which keeps its name unless deprecated too.
+func flagNames[T Flags]() (names [64]string, declared uint64) {
+	bitsOf := make(map[string][]int) // name => bits
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		if name := bit.String(); name != "" && !strings.ContainsAny(name, "|=") {
+			bitsOf[name] = append(bitsOf[name], i)
+		}
+		names[i] = "0x" + strconv.FormatUint(1<<i, 16)
+	}
+
+	for name, shared := range bitsOf {
+		var kept []int
+		for _, i := range shared {
+			if len(shared) == 1 || !isDeprecated(T(1)<<i) {
+				kept = append(kept, i)
+			}
+		}
+		if len(kept) == 1 {
+			names[kept[0]] = name
+			declared |= 1 << kept[0]
+		}
+	}
+	return
+}
+
+// isDeprecated reports whether the flag is deprecated, by the generated IsDeprecated method, if any.
+func isDeprecated(flag any) bool {
+	d, ok := flag.(interface{ IsDeprecated() (string, bool) })
+	if !ok {
+		return false
+	}
+	_, ok = d.IsDeprecated()
+	return ok
+}
+
+// Publish publishes the counter with the name, as expvar.Publish does.
+func (c *Counter[T]) Publish(name string) *Counter[T] {
+	expvar.Publish(name, c)
+	return c
+}
+
+// Observe increments the counters of the flags set in the value, without lock nor allocation.
+func (c *Counter[T]) Observe(v T) {
+	for u := uint64(v) & c.mask; u != 0; u &= u - 1 {
+		atomic.AddUint64(&c.counts[bits.TrailingZeros64(u)], 1)
+	}
+}
+
+// Count returns the number of observations of a single flag.
+func (c *Counter[T]) Count(flag T) uint64 {
+	u := uint64(flag) & c.mask
+	if u == 0 || u&(u-1) != 0 {
+		return 0
+	}
+	return atomic.LoadUint64(&c.counts[bits.TrailingZeros64(u)])
+}
+
+// Snapshot returns the counts keyed by the flag names, with the declared flags
+// never observed, but without the undeclared ones.
+func (c *Counter[T]) Snapshot() map[string]uint64 {
+	m := make(map[string]uint64)
+	for i := range c.counts {
+		if n := atomic.LoadUint64(&c.counts[i]); n > 0 || c.declared&(1<<i) != 0 {
+			m[c.names[i]] = n
+		}
+	}
+	return m
+}
+
+// String returns the JSON object of the counts keyed by the flag names, for expvar.
+func (c *Counter[T]) String() string {
+	b, err := json.Marshal(c.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
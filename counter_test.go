@@ -0,0 +1,112 @@
+package bitflags
+
+import (
+	"encoding/json"
+	"expvar"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func (p pill) String() string {
+	switch p {
+	case placebo:
+		return "Placebo"
+	case aspirin:
+		return "Aspirin"
+	case ibuprofen:
+		return "Ibuprofen"
+	case paracetamol:
+		return "Paracetamol"
+	}
+	return ""
+}
+
+// dose is printed as the generated code does, with the deprecated flag legacy replaced by high,
+// and a Level field of the bits 4..5.
+type dose uint8
+
+const (
+	low dose = 1 << iota
+	high
+	legacy
+)
+
+func (d dose) String() string {
+	var names []string
+	if d&low != 0 {
+		names = append(names, "Low")
+	}
+	if d&high != 0 || d&legacy != 0 {
+		names = append(names, "High")
+	}
+	if l := d >> 4 & 3; l != 0 {
+		names = append(names, "Level="+strconv.Itoa(int(l)))
+	}
+	return strings.Join(names, "|")
+}
+
+func (d dose) IsDeprecated() (string, bool) {
+	if d == legacy {
+		return "use High.", true
+	}
+	return "", false
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter[pill]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				c.Observe(placebo | aspirin)
+				c.Observe(aspirin | 0x80)
+			}
+		}()
+	}
+	wg.Wait()
+
+	want := map[string]uint64{"Placebo": 4000, "Aspirin": 8000, "Ibuprofen": 0, "Paracetamol": 0, "0x80": 4000}
+	if got := c.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if n := c.Count(aspirin); n != 8000 {
+		t.Errorf("got %d aspirins", n)
+	}
+
+	if expvar.Get("pills") == nil { // Once, when the test is repeated.
+		c.Publish("pills")
+	}
+
+	var got map[string]uint64
+	if err := json.Unmarshal([]byte(expvar.Get("pills").String()), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got expvar %v, want %v", got, want)
+	}
+}
+
+func TestCounterNames(t *testing.T) {
+	c := NewCounter[dose]()
+	c.Observe(low | legacy)
+	c.Observe(high | 0x30)
+
+	want := map[string]uint64{"Low": 1, "High": 1, "0x4": 1, "0x10": 1, "0x20": 1}
+	if got := c.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCounterAllocs(t *testing.T) {
+	c := NewCounter[pill]()
+
+	if n := testing.AllocsPerRun(100, func() { c.Observe(placebo | ibuprofen) }); n != 0 {
+		t.Errorf("got %v allocations per observation", n)
+	}
+}
@@ -0,0 +1,63 @@
+package bitflags
+
+import "strings"
+
+// Diff returns the flags set in new but not in old, and those set in old but not in new.
+func Diff[T Bits](old, new T) (added, removed T) {
+	return new &^ old, old &^ new
+}
+
+// Delta is the flags added and removed by a change, e.g. for audit logs.
+type Delta[T Flags] struct {
+	Added   T
+	Removed T
+}
+
+// DeltaOf returns the delta of the change of the flags from old to new.
+func DeltaOf[T Flags](old, new T) Delta[T] {
+	added, removed := Diff(old, new)
+	return Delta[T]{Added: added, Removed: removed}
+}
+
+// IsZero reports whether no flag was added nor removed.
+func (d Delta[T]) IsZero() bool {
+	return d.Added == 0 && d.Removed == 0
+}
+
+// String returns the names of the flags added, prefixed by '+', then those of the flags removed,
+// prefixed by '-', separated by spaces, e.g. "+Aspirin -Placebo".
+//
+// The flags are named by their String method, or by their hexadecimal value for the undeclared ones, e.g. "+0x80",
+// and for those without a name of their own, such as the bits of the fields, or the deprecated flags.
+func (d Delta[T]) String() string {
+	var b strings.Builder
+	for _, c := range [...]struct {
+		sign  byte
+		flags T
+	}{{'+', d.Added}, {'-', d.Removed}} {
+		for _, name := range bitNames(c.flags) {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteByte(c.sign)
+			b.WriteString(name)
+		}
+	}
+	return b.String()
+}
+
+// bitNames returns the names of the flags set, one per bit, as named by the Counter, see flagNames.
+func bitNames[T Flags](v T) []string {
+	if v == 0 {
+		return nil
+	}
+
+	var names []string
+	all, _ := flagNames[T]()
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		if v&bit != 0 {
+			names = append(names, all[i])
+		}
+	}
+	return names
+}
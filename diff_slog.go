@@ -0,0 +1,18 @@
+//go:build go1.21
+
+package bitflags
+
+import "log/slog"
+
+// LogValue returns a group of the names of the flags added and removed, as the "added" and "removed" attributes,
+// omitted when empty.
+func (d Delta[T]) LogValue() slog.Value {
+	var attrs []slog.Attr
+	if d.Added != 0 {
+		attrs = append(attrs, slog.Any("added", bitNames(d.Added)))
+	}
+	if d.Removed != 0 {
+		attrs = append(attrs, slog.Any("removed", bitNames(d.Removed)))
+	}
+	return slog.GroupValue(attrs...)
+}
@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package bitflags
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+)
+
+func TestDeltaLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	logger.Info("changed", "pill", DeltaOf(placebo|0x80, aspirin|ibuprofen))
+	logger.Info("unchanged", "pill", DeltaOf(placebo, placebo))
+
+	want := `level=INFO msg=changed pill.added="[Aspirin Ibuprofen]" pill.removed="[Placebo 0x80]"
+level=INFO msg=unchanged
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,45 @@
+package bitflags
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	added, removed := Diff(placebo|aspirin, aspirin|ibuprofen)
+	if added != ibuprofen || removed != placebo {
+		t.Errorf("Diff() = %v, %v, want %v, %v", added, removed, ibuprofen, placebo)
+	}
+
+	for _, tc := range []struct {
+		old, new pill
+		want     string
+	}{
+		{placebo, placebo, ""},
+		{placebo, aspirin, "+Aspirin -Placebo"},
+		{0, placebo | ibuprofen, "+Placebo +Ibuprofen"},
+		{aspirin | paracetamol, aspirin, "-Paracetamol"},
+		{placebo | 0x80, aspirin, "+Aspirin -Placebo -0x80"},
+		{0, 0x80, "+0x80"},
+	} {
+		d := DeltaOf(tc.old, tc.new)
+		if got := d.String(); got != tc.want {
+			t.Errorf("DeltaOf(%#x, %#x) = %q, want %q", tc.old, tc.new, got, tc.want)
+		}
+		if d.IsZero() != (tc.want == "") {
+			t.Errorf("DeltaOf(%#x, %#x).IsZero() = %v", tc.old, tc.new, d.IsZero())
+		}
+	}
+}
+
+func TestDeltaNames(t *testing.T) {
+	for _, tc := range []struct {
+		old, new dose
+		want     string
+	}{
+		{low, high, "+High -Low"},
+		{high, legacy, "+0x4 -High"},
+		{0, 0x50, "+0x10 +0x40"},
+	} {
+		if got := DeltaOf(tc.old, tc.new).String(); got != tc.want {
+			t.Errorf("DeltaOf(%#x, %#x) = %q, want %q", tc.old, tc.new, got, tc.want)
+		}
+	}
+}
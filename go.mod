@@ -6,6 +6,7 @@ require (
 	github.com/jpillora/opts v1.2.3
 	golang.org/x/mod v0.16.0
 	golang.org/x/tools v0.19.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,357 @@
+package index
+
+import "math/bits"
+
+// arrayMax is the cardinality above which a container is stored as a dense bitmap.
+const arrayMax = 4096
+
+// Bitmap is a compressed set of row IDs, partitioned by their 16 high bits in containers
+// holding either a sorted array of the 16 low bits of sparse rows, or a dense bitmap.
+//
+// The zero Bitmap is empty and ready to use. A Bitmap isn't safe for concurrent use.
+type Bitmap struct {
+	keys       []uint16 // The 16 high bits of the rows of each container, sorted.
+	containers []*container
+}
+
+type container struct {
+	n     int      // The number of rows.
+	array []uint16 // The sorted low bits of the rows, for the sparse containers.
+	words []uint64 // The bitmap of the low bits, for the dense containers.
+}
+
+// NewBitmap returns a bitmap of the rows.
+func NewBitmap(rows ...uint32) *Bitmap {
+	b := &Bitmap{}
+	for _, row := range rows {
+		b.Add(row)
+	}
+	return b
+}
+
+// Len returns the number of rows of the bitmap.
+func (b *Bitmap) Len() (n int) {
+	for _, c := range b.containers {
+		n += c.n
+	}
+	return
+}
+
+// Contains reports whether the row is in the bitmap.
+func (b *Bitmap) Contains(row uint32) bool {
+	i, ok := b.search(uint16(row >> 16))
+	return ok && b.containers[i].contains(uint16(row))
+}
+
+// Add adds the row to the bitmap.
+func (b *Bitmap) Add(row uint32) {
+	key := uint16(row >> 16)
+	i, ok := b.search(key)
+	if !ok {
+		b.keys = append(b.keys, 0)
+		copy(b.keys[i+1:], b.keys[i:])
+		b.keys[i] = key
+		b.containers = append(b.containers, nil)
+		copy(b.containers[i+1:], b.containers[i:])
+		b.containers[i] = &container{}
+	}
+	b.containers[i].add(uint16(row))
+}
+
+// Remove removes the row from the bitmap.
+func (b *Bitmap) Remove(row uint32) {
+	i, ok := b.search(uint16(row >> 16))
+	if !ok {
+		return
+	}
+	if c := b.containers[i]; c.remove(uint16(row)) && c.n == 0 {
+		b.keys = append(b.keys[:i], b.keys[i+1:]...)
+		b.containers = append(b.containers[:i], b.containers[i+1:]...)
+	}
+}
+
+// Rows returns the rows of the bitmap, in increasing order.
+func (b *Bitmap) Rows() []uint32 {
+	rows := make([]uint32, 0, b.Len())
+	b.Range(func(row uint32) bool {
+		rows = append(rows, row)
+		return true
+	})
+	return rows
+}
+
+// Range calls f for each row of the bitmap, in increasing order, until f returns false.
+func (b *Bitmap) Range(f func(row uint32) bool) {
+	for i, c := range b.containers {
+		high := uint32(b.keys[i]) << 16
+		if c.words == nil {
+			for _, low := range c.array {
+				if !f(high | uint32(low)) {
+					return
+				}
+			}
+			continue
+		}
+		for j, w := range c.words {
+			for w != 0 {
+				low := uint32(j*64 + bits.TrailingZeros64(w))
+				if !f(high | low) {
+					return
+				}
+				w &= w - 1
+			}
+		}
+	}
+}
+
+// Clone returns a copy of the bitmap.
+func (b *Bitmap) Clone() *Bitmap {
+	c := &Bitmap{
+		keys:       append([]uint16(nil), b.keys...),
+		containers: make([]*container, len(b.containers)),
+	}
+	for i, x := range b.containers {
+		c.containers[i] = x.clone()
+	}
+	return c
+}
+
+// And returns the rows in both bitmaps.
+func (b *Bitmap) And(other *Bitmap) *Bitmap {
+	r := &Bitmap{}
+	for i, j := 0, 0; i < len(b.keys) && j < len(other.keys); {
+		switch {
+		case b.keys[i] < other.keys[j]:
+			i++
+		case b.keys[i] > other.keys[j]:
+			j++
+		default:
+			r.append(b.keys[i], combine(b.containers[i], other.containers[j], func(x, y uint64) uint64 { return x & y }))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// Or returns the rows in any of the bitmaps.
+func (b *Bitmap) Or(other *Bitmap) *Bitmap {
+	r := &Bitmap{}
+	i, j := 0, 0
+	for i < len(b.keys) || j < len(other.keys) {
+		switch {
+		case j == len(other.keys) || i < len(b.keys) && b.keys[i] < other.keys[j]:
+			r.append(b.keys[i], b.containers[i].clone())
+			i++
+		case i == len(b.keys) || b.keys[i] > other.keys[j]:
+			r.append(other.keys[j], other.containers[j].clone())
+			j++
+		default:
+			r.append(b.keys[i], combine(b.containers[i], other.containers[j], func(x, y uint64) uint64 { return x | y }))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// AndNot returns the rows of the bitmap which aren't in the other one.
+func (b *Bitmap) AndNot(other *Bitmap) *Bitmap {
+	r := &Bitmap{}
+	for i, j := 0, 0; i < len(b.keys); {
+		switch {
+		case j == len(other.keys) || b.keys[i] < other.keys[j]:
+			r.append(b.keys[i], b.containers[i].clone())
+			i++
+		case b.keys[i] > other.keys[j]:
+			j++
+		default:
+			r.append(b.keys[i], combine(b.containers[i], other.containers[j], func(x, y uint64) uint64 { return x &^ y }))
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// rangeBitmap returns a bitmap of the rows from 0 to n-1.
+func rangeBitmap(n uint32) *Bitmap {
+	r := &Bitmap{}
+	for high := uint32(0); high < 1<<16 && high<<16 < n; high++ {
+		c := &container{words: make([]uint64, 1024)}
+		for low := uint32(0); low < 1<<16 && high<<16|low < n; low += 64 {
+			if rest := n - (high<<16 | low); rest < 64 {
+				c.words[low/64] = 1<<rest - 1
+				c.n += int(rest)
+			} else {
+				c.words[low/64] = ^uint64(0)
+				c.n += 64
+			}
+		}
+		r.append(uint16(high), c.compact())
+	}
+	return r
+}
+
+// append appends a non-empty container of a key greater than the last one.
+func (b *Bitmap) append(key uint16, c *container) {
+	if c.n > 0 {
+		b.keys = append(b.keys, key)
+		b.containers = append(b.containers, c)
+	}
+}
+
+// search returns the index of the container of the key, or where it would be inserted.
+func (b *Bitmap) search(key uint16) (int, bool) {
+	lo, hi := 0, len(b.keys)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if b.keys[mid] < key {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, lo < len(b.keys) && b.keys[lo] == key
+}
+
+func (c *container) contains(low uint16) bool {
+	if c.words != nil {
+		return c.words[low/64]&(1<<(low%64)) != 0
+	}
+	_, ok := searchArray(c.array, low)
+	return ok
+}
+
+func (c *container) add(low uint16) {
+	if c.words != nil {
+		if w := &c.words[low/64]; *w&(1<<(low%64)) == 0 {
+			*w |= 1 << (low % 64)
+			c.n++
+		}
+		return
+	}
+
+	i, ok := searchArray(c.array, low)
+	if ok {
+		return
+	}
+	c.array = append(c.array, 0)
+	copy(c.array[i+1:], c.array[i:])
+	c.array[i] = low
+	c.n++
+
+	if c.n > arrayMax {
+		c.words = c.dense()
+		c.array = nil
+	}
+}
+
+// remove removes the low bits, reporting whether they were present.
+func (c *container) remove(low uint16) bool {
+	if c.words != nil {
+		if w := &c.words[low/64]; *w&(1<<(low%64)) != 0 {
+			*w &^= 1 << (low % 64)
+			c.n--
+			if c.n <= arrayMax {
+				*c = *c.compact()
+			}
+			return true
+		}
+		return false
+	}
+
+	i, ok := searchArray(c.array, low)
+	if ok {
+		c.array = append(c.array[:i], c.array[i+1:]...)
+		c.n--
+	}
+	return ok
+}
+
+// dense returns the bitmap of the container.
+func (c *container) dense() []uint64 {
+	if c.words != nil {
+		return c.words
+	}
+	words := make([]uint64, 1024)
+	for _, low := range c.array {
+		words[low/64] |= 1 << (low % 64)
+	}
+	return words
+}
+
+// compact converts a dense container to an array one, if sparse enough.
+func (c *container) compact() *container {
+	if c.words == nil || c.n > arrayMax {
+		return c
+	}
+	array := make([]uint16, 0, c.n)
+	for j, w := range c.words {
+		for w != 0 {
+			array = append(array, uint16(j*64+bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+	return &container{n: c.n, array: array}
+}
+
+func (c *container) clone() *container {
+	return &container{
+		n:     c.n,
+		array: append([]uint16(nil), c.array...),
+		words: append([]uint64(nil), c.words...),
+	}
+}
+
+// combine returns the container of the word-wise operation of the containers.
+func combine(x, y *container, op func(x, y uint64) uint64) *container {
+	if x.words == nil && y.words == nil {
+		// Merge the arrays, applying the operation to the presence of each row.
+		r := &container{}
+		for i, j := 0, 0; i < len(x.array) || j < len(y.array); {
+			var low uint16
+			var a, b uint64
+			switch {
+			case j == len(y.array) || i < len(x.array) && x.array[i] < y.array[j]:
+				low, a = x.array[i], 1
+				i++
+			case i == len(x.array) || x.array[i] > y.array[j]:
+				low, b = y.array[j], 1
+				j++
+			default:
+				low, a, b = x.array[i], 1, 1
+				i++
+				j++
+			}
+			if op(a, b)&1 != 0 {
+				r.array = append(r.array, low)
+			}
+		}
+		if r.n = len(r.array); r.n > arrayMax {
+			r.words, r.array = r.dense(), nil
+		}
+		return r
+	}
+
+	xw, yw := x.dense(), y.dense()
+	r := &container{words: make([]uint64, 1024)}
+	for i := range r.words {
+		r.words[i] = op(xw[i], yw[i])
+		r.n += bits.OnesCount64(r.words[i])
+	}
+	return r.compact()
+}
+
+func searchArray(array []uint16, low uint16) (int, bool) {
+	lo, hi := 0, len(array)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		if array[mid] < low {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, lo < len(array) && array[lo] == low
+}
@@ -0,0 +1,90 @@
+package index
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// randomRows returns n random rows, clustered so that some containers are dense.
+func randomRows(r *rand.Rand, n int) map[uint32]bool {
+	rows := make(map[uint32]bool)
+	for len(rows) < n {
+		if r.Intn(2) == 0 {
+			rows[uint32(r.Intn(10000))] = true // Dense.
+		} else {
+			rows[r.Uint32()%(1<<20)] = true // Sparse.
+		}
+	}
+	return rows
+}
+
+func sorted(rows map[uint32]bool) []uint32 {
+	s := make([]uint32, 0, len(rows))
+	for row := range rows {
+		s = append(s, row)
+	}
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s
+}
+
+func TestBitmap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	x, y := randomRows(r, 9000), randomRows(r, 9000)
+	bx, by := NewBitmap(sorted(x)...), NewBitmap(sorted(y)...)
+
+	and, or, andNot := make(map[uint32]bool), make(map[uint32]bool), make(map[uint32]bool)
+	for row := range x {
+		or[row] = true
+		if y[row] {
+			and[row] = true
+		} else {
+			andNot[row] = true
+		}
+	}
+	for row := range y {
+		or[row] = true
+	}
+
+	for _, test := range []struct {
+		name string
+		got  *Bitmap
+		want map[uint32]bool
+	}{
+		{"x", bx, x},
+		{"and", bx.And(by), and},
+		{"or", bx.Or(by), or},
+		{"andNot", bx.AndNot(by), andNot},
+	} {
+		if got, want := test.got.Rows(), sorted(test.want); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %d rows, want %d", test.name, len(got), len(want))
+		}
+		if got := test.got.Len(); got != len(test.want) {
+			t.Errorf("%s: got len %d, want %d", test.name, got, len(test.want))
+		}
+	}
+
+	for row := range x {
+		if !bx.Contains(row) {
+			t.Fatalf("missing row %d", row)
+		}
+		bx.Remove(row)
+	}
+	if bx.Len() != 0 || len(bx.containers) != 0 {
+		t.Errorf("got %d rows after removing all", bx.Len())
+	}
+}
+
+func TestRangeBitmap(t *testing.T) {
+	for _, n := range []uint32{0, 1, 63, 64, 65, 4096, 1 << 16, 1<<16 + 1, 200000} {
+		b := rangeBitmap(n)
+		if b.Len() != int(n) {
+			t.Errorf("%d: got len %d", n, b.Len())
+		}
+		if n > 0 && (!b.Contains(n-1) || b.Contains(n)) {
+			t.Errorf("%d: wrong bounds", n)
+		}
+	}
+}
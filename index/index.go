@@ -0,0 +1,196 @@
+// Package index answers the queries by flags of large collections of rows, with a
+// compressed bitmap of the rows per bit of the flags.
+package index
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/flier/go-bitflags"
+)
+
+// maxRows is the maximum number of rows of an index, whose number, as well as the row IDs, fits in a uint32.
+var maxRows uint64 = math.MaxUint32
+
+// Index is a bitmap index of the flags of rows, identified by their position.
+//
+// It is safe for concurrent readers along with a single writer.
+type Index[T bitflags.Bits] struct {
+	mu   sync.RWMutex
+	rows []T
+	bits [64]*Bitmap // The rows with each bit set, allocated on demand.
+}
+
+// New returns an empty index.
+func New[T bitflags.Bits]() *Index[T] {
+	return &Index[T]{}
+}
+
+// Build returns the index of the rows, panicking past 2^32-1 rows, as Append does.
+func Build[T bitflags.Bits](rows []T) *Index[T] {
+	x := &Index[T]{rows: make([]T, 0, len(rows))}
+	for _, v := range rows {
+		x.append(v)
+	}
+	return x
+}
+
+// Len returns the number of rows.
+func (x *Index[T]) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return len(x.rows)
+}
+
+// Get returns the flags of the row.
+func (x *Index[T]) Get(row uint32) T {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.rows[row]
+}
+
+// Append appends a row of the flags, returning its ID.
+//
+// It panics if the index already has 2^32-1 rows, since the IDs are uint32.
+func (x *Index[T]) Append(v T) uint32 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.append(v)
+}
+
+func (x *Index[T]) append(v T) uint32 {
+	if uint64(len(x.rows)) >= maxRows {
+		panic(fmt.Sprintf("index: more than %d rows", maxRows))
+	}
+
+	row := uint32(len(x.rows))
+	x.rows = append(x.rows, v)
+	x.update(row, 0, v)
+	return row
+}
+
+// Set updates the flags of the row.
+func (x *Index[T]) Set(row uint32, v T) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	old := x.rows[row]
+	x.rows[row] = v
+	x.update(row, old, v)
+}
+
+// update updates the bitmaps of the bits changed from old to v.
+func (x *Index[T]) update(row uint32, old, v T) {
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		switch {
+		case v&bit != 0 && old&bit == 0:
+			if x.bits[i] == nil {
+				x.bits[i] = &Bitmap{}
+			}
+			x.bits[i].Add(row)
+		case v&bit == 0 && old&bit != 0:
+			x.bits[i].Remove(row)
+		}
+	}
+}
+
+// All returns the rows with all the flags of the mask set, or all the rows for an empty mask.
+func (x *Index[T]) All(mask T) *Bitmap {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.all(mask)
+}
+
+// Any returns the rows with any of the flags of the mask set.
+func (x *Index[T]) Any(mask T) *Bitmap {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.any(mask)
+}
+
+// None returns the rows with none of the flags of the mask set.
+func (x *Index[T]) None(mask T) *Bitmap {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return rangeBitmap(uint32(len(x.rows))).AndNot(x.any(mask))
+}
+
+// Not returns the rows which aren't in the bitmap.
+func (x *Index[T]) Not(b *Bitmap) *Bitmap {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return rangeBitmap(uint32(len(x.rows))).AndNot(b)
+}
+
+// Match returns the rows matching the predicate, such as compiled by bitflags.Compile.
+func (x *Index[T]) Match(p bitflags.Predicate[T]) *Bitmap {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	r := &Bitmap{}
+	for _, t := range p.Terms() {
+		r = r.Or(x.all(t.Required).AndNot(x.any(t.Forbidden)))
+	}
+	return r
+}
+
+// Count returns the number of rows with all the flags of the required mask set,
+// and none of the forbidden ones.
+func (x *Index[T]) Count(required, forbidden T) int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.all(required).AndNot(x.any(forbidden)).Len()
+}
+
+// Counts returns the number of rows with each bit set, indexed by the position of the bit.
+func (x *Index[T]) Counts() (counts [64]int) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	for i, b := range x.bits {
+		if b != nil {
+			counts[i] = b.Len()
+		}
+	}
+	return
+}
+
+func (x *Index[T]) all(mask T) *Bitmap {
+	var r *Bitmap
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		if mask&bit == 0 {
+			continue
+		}
+		if x.bits[i] == nil {
+			return &Bitmap{}
+		}
+		if r == nil {
+			r = x.bits[i].Clone()
+		} else {
+			r = r.And(x.bits[i])
+		}
+	}
+	if r == nil {
+		return rangeBitmap(uint32(len(x.rows)))
+	}
+	return r
+}
+
+func (x *Index[T]) any(mask T) *Bitmap {
+	r := &Bitmap{}
+	for i, bit := 0, T(1); bit != 0; i, bit = i+1, bit<<1 {
+		if mask&bit != 0 && x.bits[i] != nil {
+			r = r.Or(x.bits[i])
+		}
+	}
+	return r
+}
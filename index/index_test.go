@@ -0,0 +1,136 @@
+package index
+
+import (
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/flier/go-bitflags"
+)
+
+type pill uint8
+
+const (
+	placebo pill = 1 << iota
+	aspirin
+	ibuprofen
+	paracetamol
+)
+
+func lookupPill(name string) (pill, bool) {
+	f, ok := map[string]pill{
+		"Placebo":     placebo,
+		"Aspirin":     aspirin,
+		"Ibuprofen":   ibuprofen,
+		"Paracetamol": paracetamol,
+	}[name]
+	return f, ok
+}
+
+// scan returns the rows matching the function, as a linear scan does.
+func scan(rows []pill, match func(pill) bool) []uint32 {
+	r := []uint32{}
+	for i, v := range rows {
+		if match(v) {
+			r = append(r, uint32(i))
+		}
+	}
+	return r
+}
+
+func TestIndex(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	rows := make([]pill, 100000)
+	for i := range rows {
+		rows[i] = pill(r.Intn(16))
+	}
+
+	x := Build(rows)
+
+	// Update some rows incrementally.
+	for i := 0; i < 1000; i++ {
+		row := uint32(r.Intn(len(rows)))
+		rows[row] = pill(r.Intn(16))
+		x.Set(row, rows[row])
+	}
+	for i := 0; i < 1000; i++ {
+		v := pill(r.Intn(16))
+		rows = append(rows, v)
+		if row := x.Append(v); row != uint32(len(rows)-1) {
+			t.Fatalf("got row %d, want %d", row, len(rows)-1)
+		}
+	}
+
+	p, err := bitflags.Compile("Aspirin & !Placebo | (Ibuprofen & Paracetamol)", lookupPill)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		name  string
+		got   *Bitmap
+		match func(pill) bool
+	}{
+		{"all", x.All(aspirin | ibuprofen), func(v pill) bool { return v&(aspirin|ibuprofen) == aspirin|ibuprofen }},
+		{"any", x.Any(aspirin | ibuprofen), func(v pill) bool { return v&(aspirin|ibuprofen) != 0 }},
+		{"none", x.None(placebo), func(v pill) bool { return v&placebo == 0 }},
+		{"not", x.Not(x.All(aspirin)), func(v pill) bool { return v&aspirin == 0 }},
+		{"match", x.Match(p), p.Match},
+		{"every", x.All(0), func(pill) bool { return true }},
+	} {
+		if got, want := test.got.Rows(), scan(rows, test.match); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %d rows, want %d", test.name, len(got), len(want))
+		}
+	}
+
+	if got, want := x.Count(aspirin, placebo), len(scan(rows, func(v pill) bool { return v&aspirin != 0 && v&placebo == 0 })); got != want {
+		t.Errorf("count: got %d, want %d", got, want)
+	}
+	if got, want := x.Counts()[1], len(scan(rows, func(v pill) bool { return v&aspirin != 0 })); got != want {
+		t.Errorf("counts: got %d, want %d", got, want)
+	}
+}
+
+func TestConcurrentReaders(t *testing.T) {
+	x := New[pill]()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if n, all := x.Count(aspirin, 0), x.Len(); n > all {
+					t.Errorf("got %d rows of %d", n, all)
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 1000; i++ {
+		row := x.Append(pill(i % 16))
+		x.Set(row/2, aspirin)
+	}
+	wg.Wait()
+}
+
+func TestAppendPastMaxRows(t *testing.T) {
+	defer func(n uint64) { maxRows = n }(maxRows)
+	maxRows = 3
+
+	x := Build([]pill{placebo, aspirin})
+	if row := x.Append(ibuprofen); row != 2 {
+		t.Fatalf("got row %d", row)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic")
+		} else if x.Len() != 3 {
+			t.Errorf("got %d rows", x.Len())
+		}
+	}()
+	x.Append(paracetamol)
+}
@@ -0,0 +1,289 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"regexp"
+	"strings"
+)
+
+// FlagSet describes a flag type and its constants.
+//
+// It is the model passed to the backends, and to the templates.
+type FlagSet struct {
+	Package    string         // Name of the package declaring the type.
+	Type       string         // Name of the flag type.
+	Underlying string         // Name of the underlying integer type, e.g. "uint8".
+	Signed     bool           // Whether the underlying type is a signed integer.
+	Doc        string         // Doc comment of the type.
+	Pos        token.Position // Position of the type declaration.
+	Directives []Directive    // The //bitflags: directives of the type.
+	Values     []Value        // Constants declared for the type, in declaration order.
+	Aliases    []Alias        // Constants declared as another name of a value.
+	Exclusive  [][]string     // The groups of the //bitflags:exclusive directives, of which a single flag may be set.
+	Requires   []Requirement  // The rules of the //bitflags:requires directives.
+	Fields     []Field        // The multi-bit fields of the //bitflags:field directives.
+	Mode       string         // The mode of the type, one of the Modes.
+	Options    Options        // Options of the generator.
+	// Declare tells the backends to declare the type and its constants,
+	// for the flag sets which are not read from Go source.
+	Declare bool
+}
+
+// Alias is a constant declared as another name of a value, e.g. Acetaminophen = Paracetamol.
+type Alias struct {
+	Name   string // The name of the constant.
+	Target string // The original name of the aliased value.
+	Doc    string // The doc comment of the constant.
+	Expr   string // The expression of the value, for the declared aliases; the target if empty.
+	// Label is the name with trimmed prefix, or the line comment text; the name if empty.
+	Label string
+}
+
+// Analyze collects the flag set of the named type declared in the package.
+func Analyze(pkg *Package, typeName string) (*FlagSet, error) {
+	obj, ok := pkg.Types.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("no type %s declared in package %s", typeName, pkg.Name)
+	}
+
+	basic, ok := obj.Type().Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return nil, fmt.Errorf("type %s is not an integer type", typeName)
+	}
+
+	fs := &FlagSet{
+		Package:    pkg.Name,
+		Type:       typeName,
+		Underlying: basic.Name(),
+		Signed:     basic.Info()&types.IsUnsigned == 0,
+		Pos:        pkg.Fset.Position(obj.Pos()),
+		Options:    pkg.Options,
+	}
+
+	if doc := pkg.typeDoc(typeName); doc != nil {
+		fs.Doc = strings.TrimSpace(doc.Text())
+		fs.Directives = parseDirectives(doc)
+	}
+
+	for _, file := range pkg.Files {
+		// Set the state for this run of the walker.
+		file.TypeName = typeName
+		file.Values = nil
+		file.Aliases = nil
+		file.Err = nil
+		if file.File != nil {
+			ast.Inspect(file.File, file.GenDecl)
+			if file.Err != nil {
+				return nil, fmt.Errorf("type %s, %w", typeName, file.Err)
+			}
+			fs.Values = append(fs.Values, file.Values...)
+			fs.Aliases = append(fs.Aliases, file.Aliases...)
+		}
+	}
+
+	if len(fs.Values) == 0 {
+		return nil, fmt.Errorf("no values defined for type %s", typeName)
+	}
+
+	for i := range fs.Values {
+		v := &fs.Values[i]
+		attrs, err := parseAttrs(v.Directives)
+		if err != nil {
+			return nil, fmt.Errorf("%s: constant %s, %w", v.Pos, v.OriginalName, err)
+		}
+		v.Attrs = attrs
+	}
+
+	fs.resolveAliases()
+
+	if err := fs.resolveReplacements(); err != nil {
+		return nil, err
+	}
+
+	if err := fs.parseMode(); err != nil {
+		return nil, fmt.Errorf("%s: type %s, %w", fs.Pos, typeName, err)
+	}
+
+	if err := fs.parseFields(); err != nil {
+		return nil, fmt.Errorf("%s: type %s, %w", fs.Pos, typeName, err)
+	}
+
+	if err := fs.parseRules(); err != nil {
+		return nil, fmt.Errorf("%s: type %s, %w", fs.Pos, typeName, err)
+	}
+
+	return fs, nil
+}
+
+// HasAttrs reports whether any value has attributes.
+func (fs *FlagSet) HasAttrs() bool {
+	for _, v := range fs.Values {
+		if len(v.Attrs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Printed returns the values printed by the String method as themselves, which aren't deprecated.
+func (fs *FlagSet) Printed() []Value {
+	values := make([]Value, 0, len(fs.Values))
+	for _, v := range fs.Values {
+		if v.Deprecated == "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Unsigned returns the unsigned integer type of the size of the underlying type, e.g. "uint8" for "int8".
+func (fs *FlagSet) Unsigned() string {
+	if fs.Signed {
+		return "u" + fs.Underlying
+	}
+	return fs.Underlying
+}
+
+// Deprecated returns the deprecated values.
+func (fs *FlagSet) Deprecated() []Value {
+	var values []Value
+	for _, v := range fs.Values {
+		if v.Deprecated != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// Mapping is the deprecated values printed as the same name by the String method.
+type Mapping struct {
+	Name        string   // The printed name, of the replacement if any, or of the deprecated value.
+	Flags       []string // The original names of the deprecated values.
+	Replacement string   // The original name of the replacement, if any.
+}
+
+// Mappings returns the names printed for the deprecated values, in declaration order:
+// that of their replacement, unless it is set too, or their own without replacement,
+// so that the printed flags parse back.
+func (fs *FlagSet) Mappings() []Mapping {
+	var mappings []Mapping
+	replaced := make(map[string]int) // replacement => index of its mapping
+	for _, v := range fs.Deprecated() {
+		if v.Replacement == "" {
+			mappings = append(mappings, Mapping{Name: v.Name, Flags: []string{v.OriginalName}})
+			continue
+		}
+		if i, ok := replaced[v.Replacement]; ok {
+			mappings[i].Flags = append(mappings[i].Flags, v.OriginalName)
+			continue
+		}
+		replaced[v.Replacement] = len(mappings)
+		mappings = append(mappings, Mapping{Name: fs.value(v.Replacement).Name, Flags: []string{v.OriginalName}, Replacement: v.Replacement})
+	}
+	return mappings
+}
+
+// Set returns the condition, on the receiver i of the String method, for the mapping to be printed.
+func (m Mapping) Set() string {
+	conds := make([]string, len(m.Flags))
+	for i, flag := range m.Flags {
+		conds[i] = "i.Contains(" + flag + ")"
+	}
+	cond := strings.Join(conds, " || ")
+	if m.Replacement != "" {
+		if len(conds) > 1 {
+			cond = "(" + cond + ")"
+		}
+		cond += " && !i.Contains(" + m.Replacement + ")"
+	}
+	return cond
+}
+
+// replacement matches the name of the replacement in a deprecation notice, e.g. "use Paracetamol".
+var replacement = regexp.MustCompile(`(?i)\buse\s+([\pL_][\pL\pN_]*)`)
+
+// resolveReplacements sets the replacements of the deprecated values, named by their notice.
+func (fs *FlagSet) resolveReplacements() error {
+	for i := range fs.Values {
+		v := &fs.Values[i]
+		m := replacement.FindStringSubmatch(v.Deprecated)
+		if m == nil {
+			continue
+		}
+
+		for _, r := range fs.Values {
+			if r.OriginalName != m[1] {
+				continue
+			}
+			if r.Deprecated != "" {
+				return fmt.Errorf("%s: replacement %s of %s is deprecated", v.Pos, r.OriginalName, v.OriginalName)
+			}
+			v.Replacement = r.OriginalName
+		}
+	}
+
+	if len(fs.Printed()) == 0 {
+		return fmt.Errorf("all the values of type %s are deprecated", fs.Type)
+	}
+
+	return nil
+}
+
+// resolveAliases points the aliases of aliases to the aliased values,
+// and drops those of constants which aren't values of the flag set.
+func (fs *FlagSet) resolveAliases() {
+	targets := make(map[string]string, len(fs.Values)+len(fs.Aliases)) // name => value name
+	for _, v := range fs.Values {
+		targets[v.OriginalName] = v.OriginalName
+	}
+
+	aliases := fs.Aliases[:0]
+	for resolved := true; resolved; {
+		resolved = false
+		for _, a := range fs.Aliases {
+			if _, ok := targets[a.Name]; !ok {
+				if target, ok := targets[a.Target]; ok {
+					targets[a.Name] = target
+					resolved = true
+				}
+			}
+		}
+	}
+
+	for _, a := range fs.Aliases {
+		if target, ok := targets[a.Name]; ok {
+			a.Target = target
+			aliases = append(aliases, a)
+		}
+	}
+	fs.Aliases = aliases
+}
+
+// typeDoc returns the doc comment of the named type.
+func (pkg *Package) typeDoc(typeName string) *ast.CommentGroup {
+	for _, file := range pkg.Files {
+		if file.File == nil {
+			continue
+		}
+		for _, decl := range file.File.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				tspec := spec.(*ast.TypeSpec) // Guaranteed to succeed as this is TYPE.
+				if tspec.Name.Name != typeName {
+					continue
+				}
+				if tspec.Doc == nil && !decl.Lparen.IsValid() {
+					return decl.Doc
+				}
+				return tspec.Doc
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package gen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const analyzeInput = `package test
+
+// Pill is a set of pills.
+//
+//bitflags:group medicine
+type Pill uint8
+
+const (
+	// Placebo is not a medicine.
+	Placebo Pill = 1 << iota
+	Aspirin //bitflags:retired
+
+	// ASA is the acetylsalicylic acid.
+	ASA   = Aspirin
+	Bayer = ASA
+	Dose  = 2
+)
+`
+
+func TestAnalyze(t *testing.T) {
+	pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(analyzeInput)}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := Analyze(pkg, "Pill")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs.Underlying != "uint8" || fs.Signed {
+		t.Errorf("got underlying %s, signed %v", fs.Underlying, fs.Signed)
+	}
+	if fs.Doc != "Pill is a set of pills." {
+		t.Errorf("got doc %q", fs.Doc)
+	}
+	if fs.Pos.Line != 6 {
+		t.Errorf("got position %v", fs.Pos)
+	}
+	if expected := []Directive{{"group", "medicine"}}; !reflect.DeepEqual(fs.Directives, expected) {
+		t.Errorf("got directives %v, expected %v", fs.Directives, expected)
+	}
+
+	if len(fs.Values) != 2 {
+		t.Fatalf("got values %v", fs.Values)
+	}
+	if v := fs.Values[0]; v.Doc != "Placebo is not a medicine." || v.Pos.Line != 10 || v.Retired {
+		t.Errorf("got %+v", v)
+	}
+	if v := fs.Values[1]; v.Doc != "" || v.Pos.Line != 11 || !v.Retired {
+		t.Errorf("got %+v", v)
+	}
+
+	expected := []Alias{
+		{Name: "ASA", Target: "Aspirin", Doc: "ASA is the acetylsalicylic acid.", Label: "ASA"},
+		{Name: "Bayer", Target: "Aspirin", Label: "Bayer"},
+	}
+	if !reflect.DeepEqual(fs.Aliases, expected) {
+		t.Errorf("got aliases %+v, expected %+v", fs.Aliases, expected)
+	}
+
+	if _, err = Analyze(pkg, "Missing"); err == nil {
+		t.Error("expected error for missing type")
+	}
+}
+
+func TestTypeErrors(t *testing.T) {
+	input := `package test
+
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << iota
+	Aspirin Pill = undefinedThing
+)
+`
+	var errs TypeErrors
+	if _, err := GenerateFromSource(map[string][]byte{"pill.go": []byte(input)}, []string{"Pill"}, Options{}); !errors.As(err, &errs) {
+		t.Fatalf("got error %v, expected type errors", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("got type errors %v", errs)
+	}
+
+	pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(analyzeInput)}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ident := range pkg.Defs {
+		if ident.Name == "Aspirin" {
+			delete(pkg.Defs, ident)
+		}
+	}
+	if _, err = Analyze(pkg, "Pill"); err == nil {
+		t.Error("expected error for a constant without value")
+	}
+}
+
+func TestDescription(t *testing.T) {
+	const input = `package test
+
+type Pill uint8
+
+const (
+	// Placebo is not
+	// a medicine.
+	Placebo Pill = 1 << iota // Sugar pill
+	Aspirin                  // Relieves pain
+	Ibuprofen                //bitflags:retired
+)
+`
+
+	for _, test := range []struct {
+		lineComment bool
+		expected    []string
+	}{
+		{false, []string{"Placebo is not a medicine.", "Relieves pain", ""}},
+		{true, []string{"Placebo is not a medicine.", "", ""}},
+	} {
+		pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{LineComment: test.lineComment})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fs, err := Analyze(pkg, "Pill")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []string
+		for _, v := range fs.Values {
+			got = append(got, v.Description())
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("line comment %v: got descriptions %q, expected %q", test.lineComment, got, test.expected)
+		}
+	}
+}
+
+func TestInvalidAttr(t *testing.T) {
+	for _, directives := range []string{
+		"//bitflags:attr =admin",
+		"//bitflags:attr since=\"v2.3",
+		"//bitflags:attr admin\n\t//bitflags:attr admin=false",
+	} {
+		input := "package test\n\ntype Pill uint8\n\nconst (\n\t" + directives + "\n\tPlacebo Pill = 1\n)\n"
+
+		pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = Analyze(pkg, "Pill"); err == nil {
+			t.Errorf("expected an error for %q", directives)
+		}
+	}
+}
+
+func TestDeprecated(t *testing.T) {
+	const input = `package test
+
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << iota
+	// Deprecated: use Paracetamol.
+	Aspirin
+	Paracetamol
+	Ibuprofen // Deprecated: use Nothing.
+	// Deprecated: use Paracetamol.
+	Acetaminophen
+)
+`
+
+	pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := Analyze(pkg, "Pill")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, v := range fs.Values {
+		got = append(got, v.OriginalName+":"+v.Deprecated+":"+v.Replacement)
+	}
+	expected := []string{"Placebo::", "Aspirin:use Paracetamol.:Paracetamol", "Paracetamol::", "Ibuprofen:use Nothing.:", "Acetaminophen:use Paracetamol.:Paracetamol"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if printed := fs.Printed(); len(printed) != 2 || printed[0].Name != "Placebo" || printed[1].Name != "Paracetamol" {
+		t.Errorf("got printed %v", printed)
+	}
+
+	var sets []string
+	for _, m := range fs.Mappings() {
+		sets = append(sets, m.Name+":"+m.Set())
+	}
+	expected = []string{
+		"Paracetamol:(i.Contains(Aspirin) || i.Contains(Acetaminophen)) && !i.Contains(Paracetamol)",
+		"Ibuprofen:i.Contains(Ibuprofen)",
+	}
+	if !reflect.DeepEqual(sets, expected) {
+		t.Errorf("got mappings %q, expected %q", sets, expected)
+	}
+}
+
+func TestRules(t *testing.T) {
+	buf, err := testdata.ReadFile("testdata/rules_in.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := ParseSource(map[string][]byte{"pill.go": buf}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := Analyze(pkg, "Pill")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exclusive := [][]string{{"Placebo", "Aspirin", "Ibuprofen"}, {"Placebo", "Paracetamol"}}
+	if !reflect.DeepEqual(fs.Exclusive, exclusive) {
+		t.Errorf("exclusive %v, want %v", fs.Exclusive, exclusive)
+	}
+
+	implications := []Implication{{"Ibuprofen", []string{"Codeine"}}, {"Paracetamol", []string{"Codeine", "Ibuprofen"}}}
+	if got := fs.Implications(); !reflect.DeepEqual(got, implications) {
+		t.Errorf("implications %v, want %v", got, implications)
+	}
+}
+
+func TestInvalidRules(t *testing.T) {
+	for _, directives := range []string{
+		"//bitflags:exclusive Placebo",
+		"//bitflags:exclusive Placebo,Nothing",
+		"//bitflags:requires Placebo",
+		"//bitflags:requires Placebo,Aspirin=>Ibuprofen",
+		"//bitflags:exclusive Placebo,Aspirin\n//bitflags:requires Placebo=>Aspirin",
+		"//bitflags:exclusive Aspirin,Ibuprofen\n//bitflags:requires Placebo=>Aspirin,Ibuprofen",
+		"//bitflags:exclusive Placebo,Ibuprofen\n//bitflags:requires Placebo=>Aspirin\n//bitflags:requires Aspirin=>Ibuprofen",
+		"//bitflags:exclusive Placebo,Both",
+	} {
+		input := "package test\n\n" + directives + "\ntype Pill uint8\n\nconst (\n\tPlacebo Pill = 1 << iota\n\tAspirin\n\tIbuprofen\n\tBoth Pill = Aspirin | Placebo\n)\n"
+
+		pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = Analyze(pkg, "Pill"); err == nil {
+			t.Errorf("expected an error for %q", directives)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	buf, err := testdata.ReadFile("testdata/fields_in.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := ParseSource(map[string][]byte{"mode.go": buf}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := Analyze(pkg, "Mode")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := []Field{{"Priority", 4, 6, "uint8"}, {"Group", 8, 15, "uint16"}}
+	if !reflect.DeepEqual(fs.Fields, fields) {
+		t.Errorf("fields %v, want %v", fs.Fields, fields)
+	}
+	if mask := fs.Fields[0].Mask(); mask != 0x70 {
+		t.Errorf("mask %#x, want 0x70", mask)
+	}
+}
+
+func TestInvalidFields(t *testing.T) {
+	for _, directives := range []string{
+		"//bitflags:field priority bits=4..6",
+		"//bitflags:field Priority",
+		"//bitflags:field Priority bits=6..4",
+		"//bitflags:field Priority bits=4..6 type=int",
+		"//bitflags:field Priority bits=4..6 color=red",
+		"//bitflags:field Priority bits=4..16",
+		"//bitflags:field Priority bits=1..2",
+		"//bitflags:field Priority bits=4..6\n//bitflags:field Group bits=6..7",
+		"//bitflags:field Priority bits=4..6\n//bitflags:field Priority bits=7",
+		"//bitflags:field Aspirin bits=4..6",
+		"//bitflags:field String bits=4..6",
+		"//bitflags:field Name bits=4..6",
+		"//bitflags:field Apply bits=4..6",
+		"//bitflags:field Placebo bits=4..6",
+		"//bitflags:field Priority bits=4..6\n//bitflags:field SetPriority bits=7",
+		"//bitflags:field Level bits=4..6",
+	} {
+		input := "package test\n\n" + directives + "\ntype Pill uint16\n\nconst (\n\tPlacebo Pill = 1 << iota\n\tAspirin\n\tSetLevel\n)\n"
+
+		pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = Analyze(pkg, "Pill"); err == nil {
+			t.Errorf("expected an error for %q", directives)
+		}
+	}
+
+	// The platform-sized types have 32 bits on the 32-bit platforms.
+	for _, typ := range []string{"int", "uint", "uintptr"} {
+		input := "package test\n\n//bitflags:field Priority bits=30..33\ntype Pill " + typ + "\n\nconst Placebo Pill = 1\n"
+
+		pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(input)}, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = Analyze(pkg, "Pill"); err == nil {
+			t.Errorf("expected an error for a field of the %s type", typ)
+		}
+	}
+}
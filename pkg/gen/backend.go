@@ -0,0 +1,323 @@
+package gen
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Backend emits the code of the analyzed flag sets.
+type Backend interface {
+	// Header writes the beginning of the output for the package.
+	Header(w io.Writer, pkg *Package) error
+
+	// Generate writes the code of the flag set.
+	Generate(w io.Writer, fs *FlagSet) error
+}
+
+// Formatter is implemented by the backends whose output isn't formatted as Go source.
+type Formatter interface {
+	Format(src []byte) ([]byte, error)
+}
+
+// Languages are the output languages of NewBackend, with the extension of their files.
+var Languages = map[string]string{
+	"go":         ".go",
+	"ts":         ".ts",
+	"python":     ".py",
+	"rust":       ".rs",
+	"jsonschema": ".schema.json",
+	"openapi":    ".openapi.json",
+}
+
+// NewBackend returns the backend of the output language of the options, Go by default.
+func NewBackend(opts Options) (Backend, error) {
+	if opts.JSON != "" && opts.JSON != JSONArray && opts.JSON != JSONString && opts.JSON != JSONInt {
+		return nil, fmt.Errorf("unknown JSON shape %s", opts.JSON)
+	}
+	if !isMode(opts.Mode) {
+		return nil, fmt.Errorf("unknown mode %s", opts.Mode)
+	}
+
+	switch opts.Lang {
+	case "", "go":
+		return NewTemplateBackend(opts.TemplateDir)
+	case "ts":
+		return TypeScriptBackend{}, nil
+	case "python":
+		return NewPythonBackend(), nil
+	case "rust":
+		return NewRustBackend(), nil
+	case "jsonschema":
+		return SchemaBackend{}, nil
+	case "openapi":
+		return SchemaBackend{OpenAPI: true}, nil
+	default:
+		return nil, fmt.Errorf("unknown output language %s", opts.Lang)
+	}
+}
+
+//go:embed templates/*
+var content embed.FS
+
+var funcs = template.FuncMap{
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"last":      func(values []Value) Value { return values[len(values)-1] },
+	"title":     title,
+	"untitle":   untitle,
+	"camel":     camel,
+	"pascal":    pascal,
+	"snake":     snake,
+	"kebab":     kebab,
+	"screaming": screaming,
+	"comment":   comment,
+	"quote":     strconv.Quote,
+	"join":      strings.Join,
+}
+
+var templates = template.Must(template.New("templates").Funcs(funcs).ParseFS(content, "templates/*.go.tmpl"))
+
+// TemplateBackend generates the Go methods of the flag sets from the embedded templates.
+type TemplateBackend struct {
+	tmpl *template.Template
+	// Names of the templates that don't override an embedded one.
+	extra []string
+}
+
+// NewTemplateBackend returns a backend using the embedded templates,
+// overridden or extended by the *.go.tmpl templates of dir, if not empty.
+func NewTemplateBackend(dir string) (*TemplateBackend, error) {
+	if dir == "" {
+		return &TemplateBackend{tmpl: templates}, nil
+	}
+
+	tmpl, err := templates.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := filepath.Glob(filepath.Join(dir, "*.go.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	b := &TemplateBackend{tmpl: tmpl}
+
+	for _, name := range names {
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		base := filepath.Base(name)
+		if tmpl.Lookup(base) == nil {
+			b.extra = append(b.extra, base)
+		}
+
+		if _, err = tmpl.New(base).Parse(string(buf)); err != nil {
+			return nil, fmt.Errorf("parse template %s, %w", name, err)
+		}
+	}
+
+	return b, nil
+}
+
+func (b *TemplateBackend) Header(w io.Writer, pkg *Package) error {
+	return b.tmpl.ExecuteTemplate(w, "header.go.tmpl", map[string]interface{}{
+		"CmdLine": cmdLine(),
+		"Package": pkg,
+		"Options": pkg.Options,
+	})
+}
+
+// cmdLine returns the command line running the generator, for the "Code generated" comment.
+func cmdLine() string {
+	return strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+}
+
+func (b *TemplateBackend) Generate(w io.Writer, fs *FlagSet) (err error) {
+	if fs.Declare {
+		if err = b.tmpl.ExecuteTemplate(w, "decl.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	// Generate code that will fail if the constants change value.
+	if err = b.tmpl.ExecuteTemplate(w, "validate.go.tmpl", fs); err != nil {
+		return
+	}
+
+	if fs.Mode == ModeEnum {
+		err = b.declareEnum(w, fs)
+	} else {
+		err = b.declareFlags(w, fs)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, name := range b.extra {
+		if err = b.tmpl.ExecuteTemplate(w, name, fs); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// declareFlags generates the methods of a set of flags.
+func (b *TemplateBackend) declareFlags(w io.Writer, fs *FlagSet) (err error) {
+	if runs := splitIntoRuns(fs.Values); len(runs) <= 10 {
+		if err = b.declareIndexAndNameVars(w, fs, runs); err != nil {
+			return
+		}
+	} else {
+		if err = b.declareMapAndNameVars(w, fs); err != nil {
+			return
+		}
+	}
+
+	if err = b.tmpl.ExecuteTemplate(w, "props.go.tmpl", fs); err != nil {
+		return
+	}
+
+	if err = b.declareDescriptions(w, fs); err != nil {
+		return
+	}
+
+	if fs.Deprecated() != nil {
+		if err = b.tmpl.ExecuteTemplate(w, "deprecated.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	if fs.HasFields() {
+		if err = b.tmpl.ExecuteTemplate(w, "fields.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	if fs.HasRules() {
+		if err = b.tmpl.ExecuteTemplate(w, "rules.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	if fs.HasAttrs() {
+		if err = b.tmpl.ExecuteTemplate(w, "attr.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	if fs.Options.JSONMethods() {
+		if err = b.tmpl.ExecuteTemplate(w, "json.go.tmpl", fs); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// declareEnum generates the methods of an enumeration, whose values are printed as
+// a single name, as the stringer command does.
+func (b *TemplateBackend) declareEnum(w io.Writer, fs *FlagSet) (err error) {
+	// The name tables hold the printed names, rather than the original ones.
+	enum := *fs
+	enum.Values = make([]Value, len(fs.Values))
+	for i, v := range fs.Values {
+		v.OriginalName = v.Name
+		enum.Values[i] = v
+	}
+
+	if runs := splitIntoRuns(enum.Values); len(runs) <= 10 {
+		err = b.declareIndexAndNameVars(w, &enum, runs)
+	} else {
+		err = b.declareMapAndNameVars(w, &enum)
+	}
+	if err != nil {
+		return
+	}
+
+	if err = b.declareDescriptions(w, fs); err != nil {
+		return
+	}
+
+	// Keep a single value of those sharing a value, as Name does.
+	var unique []Value
+	for _, run := range splitIntoRuns(fs.Values) {
+		unique = append(unique, run...)
+	}
+
+	return b.tmpl.ExecuteTemplate(w, "enum.go.tmpl", struct {
+		*FlagSet
+		Unique []Value
+	}{fs, unique})
+}
+
+// declareDescriptions generates the Description method and the Help function.
+func (b *TemplateBackend) declareDescriptions(w io.Writer, fs *FlagSet) error {
+	// Keep a single value of those sharing a value, as Name does, for the cases of the switch.
+	var described []Value
+	for _, run := range splitIntoRuns(fs.Values) {
+		for _, v := range run {
+			if v.Description() != "" {
+				described = append(described, v)
+			}
+		}
+	}
+
+	return b.tmpl.ExecuteTemplate(w, "desc.go.tmpl", struct {
+		*FlagSet
+		Described []Value
+	}{fs, described})
+}
+
+func (b *TemplateBackend) declareIndexAndNameVars(w io.Writer, fs *FlagSet, runs [][]Value) error {
+	var indexes [][]int
+	for i, run := range runs {
+		if len(run) > 1 {
+			if indexes == nil {
+				indexes = make([][]int, len(runs))
+			}
+
+			indexes[i] = make([]int, len(run))
+			off := 0
+			for j, v := range run {
+				off += len(v.OriginalName)
+				indexes[i][j] = off
+			}
+		}
+	}
+
+	return b.tmpl.ExecuteTemplate(w, "index_and_name.go.tmpl", struct {
+		*FlagSet
+		Runs    [][]Value
+		Indexes [][]int
+	}{fs, runs, indexes})
+}
+
+func (b *TemplateBackend) declareMapAndNameVars(w io.Writer, fs *FlagSet) error {
+	type value struct {
+		Value
+		Start, End int
+	}
+
+	values := make([]value, len(fs.Values))
+	off := 0
+
+	for i, v := range fs.Values {
+		values[i] = value{v, off, off + len(v.OriginalName)}
+		off += len(v.OriginalName)
+	}
+
+	return b.tmpl.ExecuteTemplate(w, "map_and_name.go.tmpl", struct {
+		*FlagSet
+		Values []value
+	}{fs, values})
+}
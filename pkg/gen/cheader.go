@@ -0,0 +1,400 @@
+package gen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Unevaluated is a macro, or an enumerator, of a C header whose value couldn't be evaluated.
+type Unevaluated struct {
+	Name string
+	Pos  token.Position
+	Expr string // The C expression of the value.
+	Err  error
+}
+
+func (u *Unevaluated) Error() string {
+	return fmt.Sprintf("%s: cannot evaluate %s = %s, %v", u.Pos, u.Name, u.Expr, u.Err)
+}
+
+// ImportCHeader parses the #define integer macros and the enum bodies of a C header,
+// without invoking a C compiler, and collects those whose names start with the prefix
+// as the flag set of the named type.
+//
+// The values may be integer literals, and expressions of them, of the other macros or
+// enumerators, with the C arithmetic, bitwise and shift operators, and integer casts.
+// The prefixed macros and enumerators which couldn't be evaluated are returned aside.
+func ImportCHeader(path, prefix, typeName string, opts Options) (*FlagSet, []*Unevaluated, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return importCHeader(path, src, prefix, typeName, opts)
+}
+
+func importCHeader(filename string, src []byte, prefix, typeName string, opts Options) (*FlagSet, []*Unevaluated, error) {
+	h := newCHeader(filename, string(src))
+
+	fs := &FlagSet{
+		Type:    typeName,
+		Doc:     fmt.Sprintf("%s are the %s* constants of %s.", typeName, prefix, filepath.Base(filename)),
+		Options: opts,
+	}
+
+	var unevaluated []*Unevaluated
+	declared := make(map[string]string)
+
+	for _, sym := range h.symbols {
+		if !strings.HasPrefix(sym.name, prefix) {
+			continue
+		}
+
+		value, err := h.eval(sym.name)
+		if err != nil {
+			unevaluated = append(unevaluated, &Unevaluated{sym.name, sym.pos, sym.text, err})
+			continue
+		}
+
+		id := identifier(strings.TrimPrefix(sym.name, prefix), typeName)
+		if other, ok := declared[id]; ok {
+			return nil, nil, fmt.Errorf("%s and %s are both imported as %s", other, sym.name, id)
+		}
+		declared[id] = sym.name
+
+		v, err := newValue(id, value, false)
+		if err != nil {
+			unevaluated = append(unevaluated, &Unevaluated{sym.name, sym.pos, sym.text, err})
+			continue
+		}
+		if sym.literal() {
+			v.Expr = sym.expr
+		}
+		fs.Values = append(fs.Values, v)
+	}
+
+	if len(fs.Values) == 0 {
+		return nil, unevaluated, fmt.Errorf("no integer macros or enumerators with prefix %s in %s", prefix, filename)
+	}
+
+	fs.Underlying = underlying(fs.Values)
+	fs.Signed = strings.HasPrefix(fs.Underlying, "int")
+	for i := range fs.Values {
+		fs.Values[i].Signed = fs.Signed
+	}
+
+	dedupAliases(fs)
+
+	return fs, unevaluated, nil
+}
+
+// cSymbol is a macro, or an enumerator, of a C header.
+type cSymbol struct {
+	name string
+	pos  token.Position
+	text string   // The C expression.
+	expr string   // The expression in Go syntax.
+	prev *cSymbol // The previous enumerator, for the enumerators without an explicit value.
+	err  error    // Why the symbol can't be evaluated, if known before the evaluation.
+}
+
+// literal reports whether the Go expression only has integer literals and binary operators,
+// so that it can be kept in the generated constant.
+func (s *cSymbol) literal() bool {
+	if s.expr == "" {
+		return false
+	}
+
+	expr, err := parser.ParseExpr(s.expr)
+	if err != nil {
+		return false
+	}
+
+	literal := true
+	ast.Inspect(expr, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.Ident, *ast.UnaryExpr:
+			literal = false
+		}
+		return literal
+	})
+	return literal
+}
+
+// cHeader is the symbols of a C header, evaluated on demand.
+type cHeader struct {
+	symbols []*cSymbol
+	byName  map[string]*cSymbol
+	values  map[string]constant.Value
+	pending map[string]bool
+}
+
+var (
+	errRecursive    = errors.New("recursive definition")
+	errNotAnInteger = errors.New("not an integer")
+	errFunctionLike = errors.New("function-like macro")
+)
+
+var (
+	// A #define directive of a macro, object-like or function-like.
+	cDefine = regexp.MustCompile(`^\s*#\s*define\s+([A-Za-z_]\w*)(\(?)(.*)$`)
+	// Any other preprocessor directive.
+	cDirective = regexp.MustCompile(`^\s*#`)
+	// An enum body.
+	cEnum = regexp.MustCompile(`\benum\b\s*(?:[A-Za-z_]\w*\s*)?(?::[^{;]*)?\{([^{}]*)\}`)
+	// An enumerator with an optional value.
+	cEnumerator = regexp.MustCompile(`^\s*([A-Za-z_]\w*)\s*(?:=\s*([\s\S]*?))?\s*$`)
+	// The suffix of an integer literal.
+	cIntSuffix = regexp.MustCompile(`\b(0[xX][0-9a-fA-F]+|0[bB][01]+|[0-9]+)(?:[uU](?:ll|LL|l|L)?|(?:ll|LL|l|L)[uU]?)\b`)
+	// A cast to an integer type.
+	cCast = regexp.MustCompile(`\(\s*(?:(?:const|volatile|unsigned|signed|char|short|int|long|size_t|ssize_t|uintptr_t|intptr_t|u?int(?:8|16|32|64)_t|__u(?:8|16|32|64)|__s(?:8|16|32|64))\b\s*)+\)`)
+)
+
+func newCHeader(filename, src string) *cHeader {
+	h := &cHeader{
+		byName:  make(map[string]*cSymbol),
+		values:  make(map[string]constant.Value),
+		pending: make(map[string]bool),
+	}
+
+	lines := strings.Split(stripCComments(src), "\n")
+
+	// The source without the preprocessor directives, for the enums, keeping the line numbers.
+	code := make([]string, len(lines))
+
+	for i := 0; i < len(lines); i++ {
+		start := i
+		line := lines[i]
+		for strings.HasSuffix(line, "\\") && i+1 < len(lines) {
+			i++
+			line = strings.TrimSuffix(line, "\\") + " " + lines[i]
+		}
+
+		if m := cDefine.FindStringSubmatch(line); m != nil {
+			text := strings.TrimSpace(m[3])
+			pos := token.Position{Filename: filename, Line: start + 1}
+			if m[2] != "" {
+				h.add(&cSymbol{name: m[1], pos: pos, text: m[2] + text, err: errFunctionLike})
+			} else if text != "" {
+				h.add(&cSymbol{name: m[1], pos: pos, text: text})
+			}
+		} else if !cDirective.MatchString(line) {
+			for j := start; j <= i; j++ {
+				code[j] = lines[j]
+			}
+		}
+	}
+
+	body := strings.Join(code, "\n")
+
+	for _, m := range cEnum.FindAllStringSubmatchIndex(body, -1) {
+		var prev *cSymbol
+
+		off := m[2]
+		for _, item := range strings.Split(body[m[2]:m[3]], ",") {
+			pos := token.Position{Filename: filename, Line: 1 + strings.Count(body[:off], "\n") + countLeadingNewlines(item)}
+			off += len(item) + 1
+
+			if strings.TrimSpace(item) == "" {
+				continue
+			}
+
+			e := cEnumerator.FindStringSubmatch(item)
+			if e == nil {
+				continue
+			}
+
+			sym := &cSymbol{name: e[1], pos: pos, text: strings.Join(strings.Fields(e[2]), " ")}
+			if sym.text == "" {
+				if prev == nil {
+					sym.text = "0"
+				} else {
+					sym.prev = prev
+					sym.text = prev.name + " + 1"
+				}
+			}
+			h.add(sym)
+			prev = sym
+		}
+	}
+
+	return h
+}
+
+func (h *cHeader) add(sym *cSymbol) {
+	if other, ok := h.byName[sym.name]; ok {
+		if other.text != sym.text {
+			other.err = fmt.Errorf("redefined as %s at %s", sym.text, sym.pos)
+		}
+		return
+	}
+
+	sym.expr = cIntSuffix.ReplaceAllString(cCast.ReplaceAllString(sym.text, ""), "$1")
+	sym.expr = strings.ReplaceAll(sym.expr, "~", "^")
+
+	h.symbols = append(h.symbols, sym)
+	h.byName[sym.name] = sym
+}
+
+// eval returns the value of the named symbol.
+func (h *cHeader) eval(name string) (constant.Value, error) {
+	if v, ok := h.values[name]; ok {
+		return v, nil
+	}
+
+	sym, ok := h.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("undefined: %s", name)
+	}
+	if sym.err != nil {
+		return nil, sym.err
+	}
+	if h.pending[name] {
+		return nil, errRecursive
+	}
+
+	h.pending[name] = true
+	defer delete(h.pending, name)
+
+	expr, err := parser.ParseExpr(sym.expr)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported expression")
+	}
+
+	v, err := h.evalExpr(expr)
+	if err != nil {
+		if sym.prev != nil {
+			return nil, fmt.Errorf("follows %s, %w", sym.prev.name, err)
+		}
+		return nil, err
+	}
+
+	h.values[name] = v
+	return v, nil
+}
+
+func (h *cHeader) evalExpr(expr ast.Expr) (constant.Value, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT && e.Kind != token.CHAR {
+			return nil, errNotAnInteger
+		}
+		if v := constant.MakeFromLiteral(e.Value, e.Kind, 0); v.Kind() == constant.Int {
+			return v, nil
+		}
+		return nil, fmt.Errorf("invalid literal %s", e.Value)
+
+	case *ast.Ident:
+		return h.eval(e.Name)
+
+	case *ast.ParenExpr:
+		return h.evalExpr(e.X)
+
+	case *ast.UnaryExpr:
+		x, err := h.evalExpr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.ADD, token.SUB, token.XOR:
+			return constant.UnaryOp(e.Op, x, 0), nil
+		}
+
+	case *ast.BinaryExpr:
+		x, err := h.evalExpr(e.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := h.evalExpr(e.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		switch e.Op {
+		case token.SHL, token.SHR:
+			s, ok := constant.Uint64Val(y)
+			if !ok || s >= 64 {
+				return nil, fmt.Errorf("invalid shift count %s", y)
+			}
+			return constant.Shift(x, e.Op, uint(s)), nil
+
+		case token.QUO, token.REM:
+			if constant.Sign(y) == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			if e.Op == token.QUO {
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y), nil // Integer division.
+			}
+			fallthrough
+
+		case token.OR, token.AND, token.XOR, token.ADD, token.SUB, token.MUL:
+			return constant.BinaryOp(x, e.Op, y), nil
+		}
+
+	case *ast.CallExpr:
+		if ident, ok := e.Fun.(*ast.Ident); ok {
+			return nil, fmt.Errorf("unsupported call of %s", ident.Name)
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported expression")
+}
+
+// stripCComments replaces the comments of C source with spaces, keeping the line breaks.
+func stripCComments(src string) string {
+	var b strings.Builder
+	b.Grow(len(src))
+
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == '"' || c == '\'':
+			// Copy the literal, so that a "//" inside it isn't a comment.
+			b.WriteByte(c)
+			for i++; i < len(src) && src[i] != c && src[i] != '\n'; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					b.WriteByte(src[i])
+					i++
+				}
+				b.WriteByte(src[i])
+			}
+			if i < len(src) {
+				b.WriteByte(src[i])
+			}
+
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			if i < len(src) {
+				b.WriteByte('\n')
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			}
+			comment := src[i : i+2+end]
+			b.WriteString(strings.Repeat("\n", strings.Count(comment, "\n")))
+			b.WriteByte(' ')
+			i += 2 + end + 1
+
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+func countLeadingNewlines(s string) int {
+	return strings.Count(s[:len(s)-len(strings.TrimLeft(s, " \t\r\n"))], "\n")
+}
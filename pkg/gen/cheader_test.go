@@ -0,0 +1,72 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+)
+
+const fooHeader = `#ifndef FOO_H
+#define FOO_H
+
+#define FOO_READ    0x01 /* read access */
+#define FOO_WRITE   0x02UL // write access
+#define FOO_EXEC    (1 << 2)
+#define FOO_RW      (FOO_READ | FOO_WRITE)
+#define FOO_ALL     (FOO_RW | \
+                     FOO_EXEC)
+#define FOO_BIT(n)  (1U << (n))
+#define FOO_SYNC    FOO_BIT(5)
+#define FOO_NAME    "foo"
+#define BAR_READ    4
+
+enum foo_mode {
+	FOO_MODE_A = (unsigned int)1 << 8,
+	FOO_MODE_B,
+	/* FOO_MODE_X = 3, */
+	FOO_MODE_C = FOO_MODE_A << 2,
+	FOO_MODE_D = ~0
+};
+
+#endif
+`
+
+func TestImportCHeader(t *testing.T) {
+	fs, unevaluated, err := importCHeader("foo.h", []byte(fooHeader), "FOO_", "Foo", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if fs.Underlying != "int32" || !fs.Signed {
+		t.Errorf("got underlying %s, expected int32", fs.Underlying)
+	}
+
+	var values []string
+	for _, v := range fs.Values {
+		values = append(values, v.Name+"="+v.Str+"("+v.Expr+")")
+	}
+	expected := "ModeD=-1() Read=1(0x01) Write=2(0x02) Rw=3() Exec=4((1 << 2)) All=7() ModeA=256(1 << 8) ModeB=257() ModeC=1024()"
+	if got := strings.Join(values, " "); got != expected {
+		t.Errorf("got values %s, expected %s", got, expected)
+	}
+
+	if len(fs.Aliases) != 0 {
+		t.Errorf("got aliases %v", fs.Aliases)
+	}
+
+	var errs []string
+	for _, u := range unevaluated {
+		errs = append(errs, u.Error())
+	}
+	expected = "foo.h:10: cannot evaluate FOO_BIT = (n)  (1U << (n)), function-like macro\n" +
+		"foo.h:11: cannot evaluate FOO_SYNC = FOO_BIT(5), unsupported call of FOO_BIT\n" +
+		"foo.h:12: cannot evaluate FOO_NAME = \"foo\", not an integer"
+	if got := strings.Join(errs, "\n"); got != expected {
+		t.Errorf("got unevaluated\n%s\nexpected\n%s", got, expected)
+	}
+}
+
+func TestImportCHeaderWithoutFlags(t *testing.T) {
+	if _, _, err := importCHeader("foo.h", []byte(fooHeader), "BAZ_", "Baz", Options{}); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,140 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeKind classifies a change between two versions of a flag type.
+type ChangeKind int
+
+const (
+	FlagAdded      ChangeKind = iota // A new name with a new value.
+	FlagRemoved                      // A name and its value are gone.
+	FlagRenamed                      // The same value under a different name.
+	FlagRenumbered                   // The same name with a different value.
+	AliasAdded                       // A new name for an existing value.
+	AliasRemoved                     // A name is gone, but its value is still declared.
+)
+
+var changeKindNames = [...]string{"added", "removed", "renamed", "renumbered", "alias added", "alias removed"}
+
+func (k ChangeKind) String() string {
+	if int(k) < len(changeKindNames) {
+		return changeKindNames[k]
+	}
+	return fmt.Sprintf("ChangeKind(%d)", int(k))
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Breaking reports whether the change breaks users of the old version.
+func (k ChangeKind) Breaking() bool {
+	return k != FlagAdded && k != AliasAdded
+}
+
+// Change describes a single difference between two versions of a flag type.
+type Change struct {
+	Kind     ChangeKind `json:"kind"`
+	Name     string     `json:"name"`               // The name in the new version, or the removed name.
+	OldName  string     `json:"old_name,omitempty"` // The name in the old version of a renamed value.
+	Value    string     `json:"value"`              // The value in the new version, or the removed value.
+	OldValue string     `json:"old_value,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case FlagRenamed:
+		return fmt.Sprintf("%s: renamed from %s (%s)", c.Name, c.OldName, c.Value)
+	case FlagRenumbered:
+		return fmt.Sprintf("%s: renumbered from %s to %s", c.Name, c.OldValue, c.Value)
+	default:
+		return fmt.Sprintf("%s: %s (%s)", c.Name, c.Kind, c.Value)
+	}
+}
+
+// CompatReport lists the changes between two versions of a flag type.
+type CompatReport struct {
+	Type    string   `json:"type"`
+	Changes []Change `json:"changes"`
+}
+
+// Breaking reports whether any of the changes is breaking.
+func (r *CompatReport) Breaking() bool {
+	for _, c := range r.Changes {
+		if c.Kind.Breaking() {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare classifies the changes from the old to the new constants of a flag type.
+func Compare(typeName string, old, new []Value) *CompatReport {
+	oldValues := make(map[string]string, len(old)) // name => value
+	newValues := make(map[string]string, len(new))
+	oldNames := make(map[string][]string) // value => names
+	newNames := make(map[string][]string)
+
+	for _, v := range old {
+		oldValues[v.OriginalName] = v.Str
+		oldNames[v.Str] = append(oldNames[v.Str], v.OriginalName)
+	}
+	for _, v := range new {
+		newValues[v.OriginalName] = v.Str
+		newNames[v.Str] = append(newNames[v.Str], v.OriginalName)
+	}
+
+	// kept reports whether any of the old names of value is still declared with it.
+	kept := func(value string) bool {
+		for _, name := range oldNames[value] {
+			if newValues[name] == value {
+				return true
+			}
+		}
+		return false
+	}
+
+	r := &CompatReport{Type: typeName}
+	renamed := make(map[string]bool)
+
+	for _, v := range old {
+		value, ok := newValues[v.OriginalName]
+		switch {
+		case ok && value != v.Str:
+			r.Changes = append(r.Changes, Change{Kind: FlagRenumbered, Name: v.OriginalName, Value: value, OldValue: v.Str})
+		case ok:
+		case kept(v.Str):
+			r.Changes = append(r.Changes, Change{Kind: AliasRemoved, Name: v.OriginalName, Value: v.Str})
+		default:
+			for _, name := range newNames[v.Str] {
+				if _, declared := oldValues[name]; !declared && !renamed[name] {
+					renamed[name] = true
+					r.Changes = append(r.Changes, Change{Kind: FlagRenamed, Name: name, OldName: v.OriginalName, Value: v.Str})
+					ok = true
+					break
+				}
+			}
+			if !ok {
+				r.Changes = append(r.Changes, Change{Kind: FlagRemoved, Name: v.OriginalName, Value: v.Str})
+			}
+		}
+	}
+
+	for _, v := range new {
+		if _, declared := oldValues[v.OriginalName]; declared || renamed[v.OriginalName] {
+			continue
+		}
+		if len(oldNames[v.Str]) > 0 {
+			r.Changes = append(r.Changes, Change{Kind: AliasAdded, Name: v.OriginalName, Value: v.Str})
+		} else {
+			r.Changes = append(r.Changes, Change{Kind: FlagAdded, Name: v.OriginalName, Value: v.Str})
+		}
+	}
+
+	sort.SliceStable(r.Changes, func(i, j int) bool { return r.Changes[i].Kind < r.Changes[j].Kind })
+
+	return r
+}
@@ -0,0 +1,32 @@
+package gen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old := valuesOf("Placebo", "1", "Aspirin", "2", "Ibuprofen", "4", "Paracetamol", "8", "Acetaminophen", "8", "Retired", "16")
+	new := valuesOf("Placebo", "1", "Asp", "2", "Ibuprofen", "32", "Paracetamol", "8", "Novel", "64", "Other", "1")
+
+	r := Compare("Pill", old, new)
+
+	expected := []Change{
+		{Kind: FlagAdded, Name: "Novel", Value: "64"},
+		{Kind: FlagRemoved, Name: "Retired", Value: "16"},
+		{Kind: FlagRenamed, Name: "Asp", OldName: "Aspirin", Value: "2"},
+		{Kind: FlagRenumbered, Name: "Ibuprofen", Value: "32", OldValue: "4"},
+		{Kind: AliasAdded, Name: "Other", Value: "1"},
+		{Kind: AliasRemoved, Name: "Acetaminophen", Value: "8"},
+	}
+	if !reflect.DeepEqual(r.Changes, expected) {
+		t.Errorf("got %v, expected %v", r.Changes, expected)
+	}
+	if !r.Breaking() {
+		t.Error("expected breaking changes")
+	}
+
+	if r = Compare("Pill", old, append(old, valuesOf("Novel", "64")...)); r.Breaking() {
+		t.Errorf("unexpected breaking changes %v", r.Changes)
+	}
+}
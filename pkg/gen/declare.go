@@ -0,0 +1,113 @@
+package gen
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"math"
+	"sort"
+	"strings"
+)
+
+// GenerateFlagSets generates the declarations and the methods of the flag sets
+// which are not read from Go source, e.g. imported ones, as a file of the package.
+func GenerateFlagSets(pkg *Package, sets []*FlagSet) ([]byte, error) {
+	b, err := NewBackend(pkg.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{pkg: pkg, backend: b, Options: pkg.Options}
+
+	if err = g.GenerateHeader(); err != nil {
+		return nil, err
+	}
+
+	for _, fs := range sets {
+		fs.Declare = true
+		if err = b.Generate(&g.buf, fs); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.Format(), nil
+}
+
+// newValue returns the value of an integer constant.
+func newValue(name string, value constant.Value, signed bool) (Value, error) {
+	if value.Kind() != constant.Int {
+		return Value{}, fmt.Errorf("constant %s is not an integer: %s", name, value)
+	}
+
+	v := Value{
+		OriginalName: name,
+		Name:         name,
+		Signed:       signed,
+		Str:          value.String(),
+	}
+
+	if i64, ok := constant.Int64Val(value); ok {
+		v.Value = uint64(i64)
+	} else if u64, ok := constant.Uint64Val(value); ok {
+		v.Value = u64
+	} else {
+		return Value{}, fmt.Errorf("constant %s overflows 64 bits: %s", name, value)
+	}
+
+	return v, nil
+}
+
+// identifier converts the name to an exported Go identifier, with the type name as a fallback prefix.
+func identifier(name, typeName string) string {
+	id := pascal(name)
+	if id == "" || !token.IsExported(id) {
+		id = typeName + id
+	}
+	return id
+}
+
+// dedupAliases moves the values sharing the value of a previous one to the aliases,
+// declared as their own constant expression, or as the kept value without one.
+//
+// The values are in the order of preference, usually the order of declaration,
+// so that the first declared constant of each value is kept.
+func dedupAliases(fs *FlagSet) {
+	names := make(map[string]string, len(fs.Values)) // value => name
+	values := fs.Values[:0]
+
+	for _, v := range fs.Values {
+		if target, ok := names[v.Str]; ok {
+			fs.Aliases = append(fs.Aliases, Alias{Name: v.OriginalName, Target: target, Doc: v.Doc, Expr: v.Expr})
+			continue
+		}
+		names[v.Str] = v.OriginalName
+		values = append(values, v)
+	}
+
+	fs.Values = values
+
+	sort.SliceStable(fs.Values, func(i, j int) bool { return byValue(fs.Values).Less(i, j) })
+}
+
+// underlying returns the type holding the values, of 32 bits like the C int if they fit, or of 64 bits,
+// and unsigned unless any value is negative.
+func underlying(values []Value) string {
+	signed := false
+	for _, v := range values {
+		if strings.HasPrefix(v.Str, "-") {
+			signed = true
+		}
+	}
+
+	size := 32
+	for _, v := range values {
+		if signed && (int64(v.Value) < math.MinInt32 || int64(v.Value) > math.MaxInt32) || !signed && v.Value > math.MaxUint32 {
+			size = 64
+		}
+	}
+
+	if signed {
+		return fmt.Sprintf("int%d", size)
+	}
+	return fmt.Sprintf("uint%d", size)
+}
@@ -0,0 +1,86 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// directivePrefix introduces a bitflags directive comment, such as //bitflags:retired.
+const directivePrefix = "//bitflags:"
+
+// Directive represents a //bitflags:<name> [args] comment attached to a declaration.
+type Directive struct {
+	Name string // The directive name, e.g. "retired".
+	Args string // The rest of the line, trimmed of spaces.
+}
+
+// parseDirectives collects the bitflags directives found in the comment groups.
+func parseDirectives(groups ...*ast.CommentGroup) (directives []Directive) {
+	for _, group := range groups {
+		if group == nil {
+			continue
+		}
+		for _, c := range group.List {
+			if !strings.HasPrefix(c.Text, directivePrefix) {
+				continue
+			}
+			name, args, _ := strings.Cut(strings.TrimPrefix(c.Text, directivePrefix), " ")
+			directives = append(directives, Directive{
+				Name: name,
+				Args: strings.TrimSpace(args),
+			})
+		}
+	}
+	return
+}
+
+// hasDirective reports whether the named directive is present.
+func hasDirective(directives []Directive, name string) bool {
+	for _, d := range directives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Attr is a key=value attribute of a constant, set by a //bitflags:attr directive.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// parseAttrs collects the attributes of the //bitflags:attr key=value directives.
+//
+// The value is the rest of the line, or a quoted Go string; a key alone sets the value "true".
+func parseAttrs(directives []Directive) (attrs []Attr, err error) {
+	for _, d := range directives {
+		if d.Name != "attr" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(d.Args, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok {
+			value = "true"
+		} else if strings.HasPrefix(value, `"`) {
+			if value, err = strconv.Unquote(value); err != nil {
+				return nil, fmt.Errorf("invalid attr %s value, %w", key, err)
+			}
+		}
+		if key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("invalid attr %q, expected key=value", d.Args)
+		}
+
+		for _, a := range attrs {
+			if a.Key == key {
+				return nil, fmt.Errorf("duplicate attr %s", key)
+			}
+		}
+
+		attrs = append(attrs, Attr{key, value})
+	}
+	return
+}
@@ -0,0 +1,160 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"math/bits"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// Documentation formats of WriteDoc.
+const (
+	DocMarkdown = "md"
+	DocHTML     = "html"
+)
+
+var (
+	mdDoc   = template.Must(template.New("doc").Funcs(template.FuncMap{"cell": mdCell}).ParseFS(content, "templates/doc.md.tmpl"))
+	htmlDoc = htmltemplate.Must(htmltemplate.New("doc").ParseFS(content, "templates/doc.html.tmpl"))
+)
+
+// DocRow is a row of the documentation table of a flag set.
+type DocRow struct {
+	Name       string   // The name of the flag, as printed by the String method.
+	Bit        string   // The positions of the bits of the value, e.g. "3" or "0, 1".
+	Hex        string   // The hexadecimal value, e.g. "0x8".
+	Aliases    []string // The names of the constants aliasing the value.
+	Deprecated string   // The deprecation notice of the doc comment, or those of the flags it replaces.
+	Doc        string   // The doc, or trailing, comment without the deprecation notice, on a single line.
+}
+
+// DocRows returns the rows of the documentation table of the flag set, one per value printed
+// by the String method: the deprecated values with a replacement are only listed with their
+// notice in the row of the replacement, e.g. "Aspirin: use Paracetamol.".
+func DocRows(fs *FlagSet) []DocRow {
+	rows := make([]DocRow, 0, len(fs.Values))
+	index := make(map[string]int, len(fs.Values)) // original name => row
+	var replaced []Value
+
+	for _, v := range fs.Values {
+		if v.Replacement != "" {
+			replaced = append(replaced, v)
+			continue
+		}
+
+		row := DocRow{Name: v.Name, Bit: bitPositions(v.Value), Hex: "0x" + strconv.FormatUint(v.Value, 16)}
+		if v.Signed && int64(v.Value) < 0 {
+			row.Bit = ""
+			row.Hex = "-0x" + strconv.FormatUint(uint64(-int64(v.Value)), 16)
+		}
+
+		for _, a := range fs.Aliases {
+			if a.Target == v.OriginalName {
+				label := a.Label
+				if label == "" {
+					label = a.Name
+				}
+				row.Aliases = append(row.Aliases, label)
+			}
+		}
+
+		text := v.Doc
+		if text == "" && v.Comment != v.Name {
+			text = v.Comment
+		}
+
+		var doc []string
+		for _, para := range strings.Split(text, "\n\n") {
+			para = strings.Join(strings.Fields(para), " ")
+			if strings.HasPrefix(para, "Deprecated: ") {
+				row.Deprecated = strings.TrimPrefix(para, "Deprecated: ")
+			} else if para != "" {
+				doc = append(doc, para)
+			}
+		}
+		row.Doc = strings.Join(doc, " ")
+		if row.Deprecated == "" {
+			row.Deprecated = v.Deprecated
+		}
+
+		index[v.OriginalName] = len(rows)
+		rows = append(rows, row)
+	}
+
+	for _, v := range replaced {
+		row := &rows[index[v.Replacement]]
+		if row.Deprecated != "" {
+			row.Deprecated += " "
+		}
+		row.Deprecated += v.Name + ": " + v.Deprecated
+	}
+
+	return rows
+}
+
+// bitPositions returns the positions of the bits set in the value.
+func bitPositions(v uint64) string {
+	var positions []string
+	for v != 0 {
+		positions = append(positions, strconv.Itoa(bits.TrailingZeros64(v)))
+		v &= v - 1
+	}
+	return strings.Join(positions, ", ")
+}
+
+// WriteDoc writes the documentation table of the flag set in the format.
+func WriteDoc(w io.Writer, format string, fs *FlagSet) error {
+	data := struct {
+		*FlagSet
+		Rows []DocRow
+	}{fs, DocRows(fs)}
+
+	switch format {
+	case DocMarkdown:
+		return mdDoc.ExecuteTemplate(w, "doc.md.tmpl", data)
+	case DocHTML:
+		return htmlDoc.ExecuteTemplate(w, "doc.html.tmpl", data)
+	default:
+		return fmt.Errorf("unknown documentation format %s", format)
+	}
+}
+
+// DocMarkers returns the comments between which ReplaceDoc writes the documentation of the named type.
+func DocMarkers(typeName string) (begin, end string) {
+	return "<!-- BEGIN bitflags " + typeName + " -->", "<!-- END bitflags " + typeName + " -->"
+}
+
+// ReplaceDoc replaces the text between the markers of the named type with the documentation.
+func ReplaceDoc(src []byte, typeName string, doc []byte) ([]byte, error) {
+	begin, end := DocMarkers(typeName)
+
+	i := bytes.Index(src, []byte(begin))
+	if i < 0 {
+		return nil, fmt.Errorf("no %s marker", begin)
+	}
+	i += len(begin)
+
+	j := bytes.Index(src[i:], []byte(end))
+	if j < 0 {
+		return nil, fmt.Errorf("no %s marker", end)
+	}
+	j += i
+
+	var buf bytes.Buffer
+	buf.Write(src[:i])
+	buf.WriteByte('\n')
+	buf.Write(bytes.TrimSpace(doc))
+	buf.WriteByte('\n')
+	buf.Write(src[j:])
+
+	return buf.Bytes(), nil
+}
+
+// mdCell escapes the text for a cell of a Markdown table.
+func mdCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, `\`, `\\`), "|", `\|`)
+}
@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const docInput = `package test
+
+type Pill uint8
+
+const (
+	// Placebo is not a medicine.
+	Placebo Pill = 1 << iota
+	// Aspirin relieves pain.
+	//
+	// Deprecated: use Paracetamol.
+	Aspirin
+	Paracetamol
+	// Both | either.
+	Mixed Pill = 5
+	// Deprecated: no longer sold.
+	Ibuprofen Pill = 8
+
+	Acetaminophen = Paracetamol
+)
+`
+
+func TestWriteDoc(t *testing.T) {
+	pkg, err := ParseSource(map[string][]byte{"pill.go": []byte(docInput)}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := Analyze(pkg, "Pill")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteDoc(&buf, DocMarkdown, fs); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "| Name | Bit | Value | Aliases | Deprecated | Description |\n" +
+		"| ---- | --: | ----: | ------- | ---------- | ----------- |\n" +
+		"| `Placebo` | 0 | `0x1` |  |  | Placebo is not a medicine. |\n" +
+		"| `Paracetamol` | 2 | `0x4` | `Acetaminophen` | Aspirin: use Paracetamol. |  |\n" +
+		"| `Mixed` | 0, 2 | `0x5` |  |  | Both \\| either. |\n" +
+		"| `Ibuprofen` | 3 | `0x8` |  | no longer sold. |  |\n"
+	if got := buf.String(); got != expected {
+		t.Errorf("got\n%s\nexpected\n%s", got, expected)
+	}
+
+	buf.Reset()
+	if err = WriteDoc(&buf, DocHTML, fs); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); !strings.Contains(got, "<tr><td><code>Paracetamol</code></td><td>2</td><td><code>0x4</code></td><td><code>Acetaminophen</code></td>") {
+		t.Errorf("got\n%s", got)
+	}
+
+	if err = WriteDoc(&buf, "pdf", fs); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestReplaceDoc(t *testing.T) {
+	readme := "# Pills\n\n<!-- BEGIN bitflags Pill -->\nstale\n<!-- END bitflags Pill -->\n\nMore.\n"
+
+	got, err := ReplaceDoc([]byte(readme), "Pill", []byte("| table |\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "# Pills\n\n<!-- BEGIN bitflags Pill -->\n| table |\n<!-- END bitflags Pill -->\n\nMore.\n"; string(got) != expected {
+		t.Errorf("got %q, expected %q", got, expected)
+	}
+
+	if _, err = ReplaceDoc([]byte(readme), "Dose", nil); err == nil {
+		t.Error("expected an error for missing markers")
+	}
+}
@@ -0,0 +1,181 @@
+package gen
+
+import (
+	"fmt"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// Field is a multi-bit integer field packed in the flags, declared by a
+// //bitflags:field Name bits=Lo..Hi type=T directive of the type.
+type Field struct {
+	Name   string
+	Lo, Hi int    // The range of bits of the field, inclusive.
+	Type   string // The unsigned integer type of the field values.
+}
+
+// Width returns the number of bits of the field.
+func (f Field) Width() int { return f.Hi - f.Lo + 1 }
+
+// Max returns the maximum value of the field.
+func (f Field) Max() uint64 { return 1<<f.Width() - 1 }
+
+// Mask returns the bits of the field in the flags.
+func (f Field) Mask() uint64 { return f.Max() << f.Lo }
+
+// fieldTypes are the bit sizes of the types allowed for the fields.
+var fieldTypes = map[string]int{"uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uint": 64}
+
+// methods are the generated methods which the getters and setters of the fields mustn't shadow.
+var methods = []string{"Contains", "Name", "String", "Names", "Description", "Attr", "IsDeprecated", "Apply", "Validate", "Normalize", "MarshalJSON", "UnmarshalJSON"}
+
+// HasFields reports whether the flag set has multi-bit fields.
+func (fs *FlagSet) HasFields() bool {
+	return len(fs.Fields) > 0
+}
+
+// parseFields collects the //bitflags:field directives of the type, refusing the
+// fields overlapping each other or the values.
+func (fs *FlagSet) parseFields() error {
+	size := fs.size()
+
+	for _, d := range fs.Directives {
+		if d.Name != "field" {
+			continue
+		}
+
+		f, err := parseField(d.Args)
+		if err != nil {
+			return fmt.Errorf("field %s, %w", d.Args, err)
+		}
+
+		limit := size
+		if fs.Signed {
+			limit-- // Keep the sign bit out of the fields, for the masks to be positive.
+		}
+		if f.Hi >= limit {
+			return fmt.Errorf("field %s, bits %d..%d overflow the %d bits of the %s type", f.Name, f.Lo, f.Hi, limit, fs.Underlying)
+		}
+		if f.Width() > fieldTypes[f.Type] {
+			return fmt.Errorf("field %s, %d bits overflow the %s type", f.Name, f.Width(), f.Type)
+		}
+
+		for _, other := range fs.Fields {
+			if other.Name == f.Name {
+				return fmt.Errorf("duplicate field %s", f.Name)
+			}
+			if other.Mask()&f.Mask() != 0 {
+				return fmt.Errorf("field %s overlaps field %s", f.Name, other.Name)
+			}
+		}
+		for _, method := range []string{f.Name, "Set" + f.Name} {
+			if err := fs.checkMethod(method); err != nil {
+				return fmt.Errorf("field %s, %w", f.Name, err)
+			}
+		}
+		for _, v := range fs.Values {
+			if v.Name == f.Name || v.OriginalName == f.Name {
+				return fmt.Errorf("field %s has the name of a value", f.Name)
+			}
+			if v.Value&f.Mask() != 0 {
+				return fmt.Errorf("field %s overlaps value %s", f.Name, v.OriginalName)
+			}
+		}
+
+		fs.Fields = append(fs.Fields, f)
+	}
+
+	return nil
+}
+
+// checkMethod refuses the name of a method of a field declared by another method:
+// a generated one, the accessor of a value, or the getter or setter of another field.
+func (fs *FlagSet) checkMethod(name string) error {
+	for _, method := range methods {
+		if name == method {
+			return fmt.Errorf("method %s is generated", name)
+		}
+	}
+	for _, v := range fs.Values {
+		if v.Name == name {
+			return fmt.Errorf("method %s is the accessor of value %s", name, v.OriginalName)
+		}
+	}
+	for _, f := range fs.Fields {
+		if name == f.Name || name == "Set"+f.Name {
+			return fmt.Errorf("method %s is declared for field %s", name, f.Name)
+		}
+	}
+	return nil
+}
+
+// parseField parses the arguments of a //bitflags:field directive, e.g. "Priority bits=4..6 type=uint8".
+// The bits may be a single bit, and the type defaults to the smallest type holding the field.
+func parseField(args string) (f Field, err error) {
+	words := strings.Fields(args)
+	if len(words) == 0 || !token.IsIdentifier(words[0]) || !token.IsExported(words[0]) {
+		return f, fmt.Errorf("expected an exported field name")
+	}
+	f.Name = words[0]
+
+	bits := false
+	for _, word := range words[1:] {
+		key, value, _ := strings.Cut(word, "=")
+		switch key {
+		case "bits":
+			lo, hi, ok := strings.Cut(value, "..")
+			if !ok {
+				hi = lo
+			}
+			if f.Lo, err = strconv.Atoi(lo); err == nil {
+				f.Hi, err = strconv.Atoi(hi)
+			}
+			if err != nil || f.Lo < 0 || f.Hi < f.Lo {
+				return f, fmt.Errorf("invalid bits %q, expected Lo..Hi", value)
+			}
+			bits = true
+
+		case "type":
+			if _, ok := fieldTypes[value]; !ok {
+				return f, fmt.Errorf("invalid type %q, expected an unsigned integer type", value)
+			}
+			f.Type = value
+
+		default:
+			return f, fmt.Errorf("unknown argument %q", word)
+		}
+	}
+
+	if !bits {
+		return f, fmt.Errorf("missing bits=Lo..Hi")
+	}
+	if f.Type == "" {
+		switch w := f.Width(); {
+		case w <= 8:
+			f.Type = "uint8"
+		case w <= 16:
+			f.Type = "uint16"
+		case w <= 32:
+			f.Type = "uint32"
+		default:
+			f.Type = "uint64"
+		}
+	}
+
+	return f, nil
+}
+
+// size returns the number of bits of the underlying type, that of the 32-bit platforms
+// for the platform-sized int, uint and uintptr.
+func (fs *FlagSet) size() int {
+	switch strings.TrimPrefix(strings.TrimPrefix(fs.Underlying, "u"), "int") {
+	case "8":
+		return 8
+	case "16":
+		return 16
+	case "32", "", "ptr":
+		return 32
+	}
+	return 64
+}
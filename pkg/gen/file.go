@@ -1,11 +1,11 @@
 package gen
 
 import (
+	"fmt"
 	"go/ast"
 	"go/constant"
 	"go/token"
 	"go/types"
-	"log"
 	"strings"
 )
 
@@ -14,11 +14,16 @@ type File struct {
 	*ast.File           // Parsed AST.
 	TypeName    string  // Name of the constant type.
 	Values      []Value // Accumulator for constant values of that type.
+	Aliases     []Alias // Accumulator for the untyped constants naming a constant of that type.
+	Err         error   // The error stopping the walk, if any.
 	TrimPrefix  string
 	LineComment bool
 }
 
 func (f *File) GenDecl(node ast.Node) bool {
+	if f.Err != nil {
+		return false
+	}
 	decl, ok := node.(*ast.GenDecl)
 	if !ok || decl.Tok != token.CONST {
 		// We only care about const declarations.
@@ -45,6 +50,7 @@ func (f *File) GenDecl(node ast.Node) bool {
 			// a type conversion.
 			ce, ok := vspec.Values[0].(*ast.CallExpr)
 			if !ok {
+				f.alias(decl, vspec)
 				continue
 			}
 			id, ok := ce.Fun.(*ast.Ident)
@@ -65,6 +71,11 @@ func (f *File) GenDecl(node ast.Node) bool {
 			// This is not the type we're looking for.
 			continue
 		}
+		// An unparenthesized declaration carries the doc comment itself.
+		doc := vspec.Doc
+		if doc == nil && !decl.Lparen.IsValid() {
+			doc = decl.Doc
+		}
 		// We now have a list of names (from one line of source code) all being
 		// declared with the desired type.
 		// Grab their names and actual values and store them in f.values.
@@ -75,22 +86,28 @@ func (f *File) GenDecl(node ast.Node) bool {
 			// This dance lets the type checker find the values for us. It's a
 			// bit tricky: look up the object declared by the name, find its
 			// types.Const, and extract its value.
+			pos := f.Fset.Position(name.Pos())
 			obj, ok := f.Package.Defs[name]
-			if !ok {
-				log.Fatalf("no value for constant %s", name)
+			if !ok || obj == nil {
+				f.Err = fmt.Errorf("%s: no value for constant %s", pos, name)
+				return false
 			}
-			info := obj.Type().Underlying().(*types.Basic).Info()
-			if info&types.IsInteger == 0 {
-				log.Fatalf("can't handle non-integer constant type %s", typ)
+			basic, ok := obj.Type().Underlying().(*types.Basic)
+			if !ok || basic.Info()&types.IsInteger == 0 {
+				f.Err = fmt.Errorf("%s: can't handle non-integer constant type %s", pos, typ)
+				return false
 			}
+			info := basic.Info()
 			value := obj.(*types.Const).Val() // Guaranteed to succeed as this is CONST.
 			if value.Kind() != constant.Int {
-				log.Fatalf("can't happen: constant is not an integer %s", name)
+				f.Err = fmt.Errorf("%s: constant %s is not an integer", pos, name)
+				return false
 			}
 			i64, isInt := constant.Int64Val(value)
 			u64, isUint := constant.Uint64Val(value)
 			if !isInt && !isUint {
-				log.Fatalf("internal error: value of %s is not an integer: %s", name, value.String())
+				f.Err = fmt.Errorf("%s: value of %s is not an integer: %s", pos, name, value.String())
+				return false
 			}
 			if !isInt {
 				u64 = uint64(i64)
@@ -100,8 +117,17 @@ func (f *File) GenDecl(node ast.Node) bool {
 				Value:        u64,
 				Signed:       info&types.IsUnsigned == 0,
 				Str:          value.String(),
+				Doc:          strings.TrimSpace(doc.Text()),
+				Comment:      strings.TrimSpace(vspec.Comment.Text()),
+				Pos:          pos,
+				Directives:   parseDirectives(doc, vspec.Comment),
 			}
-			if c := vspec.Comment; f.LineComment && c != nil && len(c.List) == 1 {
+			v.Retired = hasDirective(v.Directives, "retired")
+			if v.Deprecated = deprecation(v.Doc); v.Deprecated == "" {
+				v.Deprecated = deprecation(v.Comment)
+			}
+			// The text of the comment has no directives, so that it is empty for a single directive.
+			if c := vspec.Comment; f.LineComment && c != nil && len(c.List) == 1 && strings.TrimSpace(c.Text()) != "" {
 				v.Name = strings.TrimSpace(c.Text())
 			} else {
 				v.Name = strings.TrimPrefix(v.OriginalName, f.TrimPrefix)
@@ -111,3 +137,38 @@ func (f *File) GenDecl(node ast.Node) bool {
 	}
 	return false
 }
+
+// alias collects the constants of the spec declared as another constant of the type,
+// e.g. "Acetaminophen = Paracetamol".
+func (f *File) alias(decl *ast.GenDecl, vspec *ast.ValueSpec) {
+	for i, name := range vspec.Names {
+		if i >= len(vspec.Values) || name.Name == "_" {
+			break
+		}
+		target, ok := vspec.Values[i].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj, ok := f.Package.Defs[name]
+		if !ok || obj == nil {
+			continue
+		}
+		named, ok := obj.Type().(*types.Named)
+		if !ok || named.Obj().Name() != f.TypeName || named.Obj().Pkg() != obj.Pkg() {
+			continue
+		}
+
+		doc := vspec.Doc
+		if doc == nil && !decl.Lparen.IsValid() {
+			doc = decl.Doc
+		}
+
+		a := Alias{Name: name.Name, Target: target.Name, Doc: strings.TrimSpace(doc.Text())}
+		if c := vspec.Comment; f.LineComment && c != nil && len(c.List) == 1 && strings.TrimSpace(c.Text()) != "" {
+			a.Label = strings.TrimSpace(c.Text())
+		} else {
+			a.Label = strings.TrimPrefix(a.Name, f.TrimPrefix)
+		}
+		f.Aliases = append(f.Aliases, a)
+	}
+}
@@ -2,140 +2,163 @@ package gen
 
 import (
 	"bytes"
-	"embed"
-	"errors"
 	"fmt"
-	"go/ast"
 	"go/format"
 	"os"
-	"path/filepath"
 	"sort"
-	"strings"
-	"text/template"
 
 	"golang.org/x/tools/go/packages"
 )
 
-//go:embed templates/*
-var content embed.FS
+// Options controls the generated code.
+type Options struct {
+	TrimPrefix  string // Prefix to trim from the constant names.
+	LineComment bool   // Use the line comment text as the flag name when present.
+	// TemplateDir holds *.go.tmpl templates which override the embedded ones by name.
+	// Templates with other names are executed for each type, after the embedded ones.
+	TemplateDir string
+	// BuildConstraint is the //go:build expression of the generated file, e.g. "linux && amd64".
+	BuildConstraint string
+	// Lang is the output language, one of the Languages; Go if empty.
+	Lang string
+	// JSON is the JSON shape of the flags, one of the JSONShapes; the default integer if empty.
+	JSON string
+	// Apply generates the Apply method of the flags, importing the github.com/flier/go-bitflags package.
+	Apply bool
+	// Mode is the kind of the types, one of the Modes; flags if empty.
+	// A //bitflags:mode directive of a type overrides it.
+	Mode string
+}
+
+// Modes of the types.
+const (
+	ModeFlags = "flags" // Sets of flags, printed as the names of the flags set, e.g. "Placebo|Aspirin".
+	ModeEnum  = "enum"  // Enumerations, printed as the name of a single value, as the stringer command does.
+)
 
-var templates = template.Must(template.New("templates").Funcs(template.FuncMap{
-	"lower": strings.ToLower,
-	"upper": strings.ToUpper,
-	"last":  func(values []Value) Value { return values[len(values)-1] },
-}).ParseFS(content, "templates/*.go.tmpl"))
+// Modes are the modes of the types.
+var Modes = []string{ModeFlags, ModeEnum}
 
-type Generator struct {
-	buf  bytes.Buffer
-	pkg  *Package                                 // Package we are scanning.
-	logf func(format string, args ...interface{}) // test logging hook; nil when not testing
+// isMode reports whether the mode is one of the Modes, or empty.
+func isMode(mode string) bool {
+	return mode == "" || mode == ModeFlags || mode == ModeEnum
+}
+
+// JSON shapes of the flags.
+const (
+	JSONArray  = "array"  // An array of the names of the flags set, e.g. ["Placebo","Aspirin"].
+	JSONString = "string" // The names of the flags set joined by "|", e.g. "Placebo|Aspirin".
+	JSONInt    = "int"    // The integer value, e.g. 3.
+)
 
-	TrimPrefix  string
-	LineComment bool
+// JSONShapes are the JSON shapes of the flags.
+var JSONShapes = []string{JSONArray, JSONString, JSONInt}
+
+// JSONMethods reports whether the JSON shape needs the generated Parse and JSON methods.
+func (o Options) JSONMethods() bool {
+	return o.JSON == JSONArray || o.JSON == JSONString
+}
+
+type Generator struct {
+	buf     bytes.Buffer
+	pkg     *Package                                 // Package we are scanning.
+	logf    func(format string, args ...interface{}) // test logging hook; nil when not testing
+	backend Backend
+
+	Options
+	Dir string   // Directory in which to load the package; the current directory if empty.
+	Env []string // Extra environment in which to load the package, e.g. GOOS=linux.
 }
 
 func New(trimPrefix string, lineComment bool) *Generator {
 	return &Generator{
-		TrimPrefix:  trimPrefix,
-		LineComment: lineComment,
+		Options: Options{
+			TrimPrefix:  trimPrefix,
+			LineComment: lineComment,
+		},
 	}
 }
 
-var (
-	ErrTooManyPackages = errors.New("too many package")
-)
-
 // ParsePackage analyzes the single package constructed from the patterns and tags.
 func (g *Generator) ParsePackage(patterns []string, tags []string) (err error) {
-	cfg := &packages.Config{
-		Mode:       packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
-		Tests:      false,
-		BuildFlags: []string{fmt.Sprintf("-tags=%s", strings.Join(tags, " "))},
-		Logf:       g.logf,
+	cfg := &packages.Config{Dir: g.Dir, Logf: g.logf}
+	if len(g.Env) > 0 {
+		cfg.Env = append(os.Environ(), g.Env...)
 	}
 
-	pkgs, err := packages.Load(cfg, patterns...)
-	if err != nil {
-		err = fmt.Errorf("load package, %w", err)
-		return
-	}
-
-	if len(pkgs) != 1 {
-		err = fmt.Errorf("%d packages matching %v, %w", len(pkgs), strings.Join(patterns, " "), ErrTooManyPackages)
-		return
-	}
-
-	g.addPackage(pkgs[0])
+	g.pkg, err = loadPackage(cfg, patterns, tags, g.Options)
 
 	return
 }
 
-// addPackage adds a type checked Package and its syntax files to the generator.
-func (g *Generator) addPackage(pkg *packages.Package) {
-	g.pkg = &Package{
-		Name:  pkg.Name,
-		Defs:  pkg.TypesInfo.Defs,
-		Files: make([]*File, len(pkg.Syntax)),
-	}
+// Package returns the package parsed by ParsePackage.
+func (g *Generator) Package() *Package {
+	return g.pkg
+}
 
-	for i, file := range pkg.Syntax {
-		g.pkg.Files[i] = &File{
-			File:        file,
-			Package:     g.pkg,
-			TrimPrefix:  g.TrimPrefix,
-			LineComment: g.LineComment,
+// Backend returns the backend emitting the code; by default that of the output language,
+// e.g. the embedded templates, overridden by those of TemplateDir, for Go.
+func (g *Generator) Backend() (Backend, error) {
+	if g.backend == nil {
+		b, err := NewBackend(g.Options)
+		if err != nil {
+			return nil, err
 		}
+		g.backend = b
 	}
+
+	return g.backend, nil
+}
+
+// SetBackend replaces the backend emitting the code.
+func (g *Generator) SetBackend(b Backend) {
+	g.backend = b
 }
 
 func (g *Generator) GenerateHeader() error {
-	return templates.Lookup("header.go.tmpl").Execute(&g.buf, map[string]interface{}{
-		"CmdLine": strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "),
-		"Package": g.pkg,
-	})
+	b, err := g.Backend()
+	if err != nil {
+		return err
+	}
+
+	// Output options, such as the build constraint, may be changed after parsing.
+	g.pkg.Options = g.Options
+
+	return b.Header(&g.buf, g.pkg)
 }
 
-// generate produces the String method for the named type.
-func (g *Generator) Generate(typeName string) (err error) {
-	values := make([]Value, 0, 100)
-	for _, file := range g.pkg.Files {
-		// Set the state for this run of the walker.
-		file.TypeName = typeName
-		file.Values = nil
-		if file.File != nil {
-			ast.Inspect(file.File, file.GenDecl)
-			values = append(values, file.Values...)
-		}
+// Values returns the constants declared for the named type.
+func (g *Generator) Values(typeName string) ([]Value, error) {
+	fs, err := Analyze(g.pkg, typeName)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(values) == 0 {
-		err = fmt.Errorf("no values defined for type %s", typeName)
+	return fs.Values, nil
+}
+
+// generate produces the String method for the named type.
+func (g *Generator) Generate(typeName string) (err error) {
+	fs, err := Analyze(g.pkg, typeName)
+	if err != nil {
 		return
 	}
 
-	// Generate code that will fail if the constants change value.
-	if err = templates.Lookup("validate.go.tmpl").Execute(&g.buf, values); err != nil {
+	b, err := g.Backend()
+	if err != nil {
 		return
 	}
 
-	if runs := splitIntoRuns(values); len(runs) <= 10 {
-		if err = g.declareIndexAndNameVars(runs, typeName); err != nil {
-			return
+	if _, ok := b.(*TemplateBackend); !ok {
+		if fs.HasFields() {
+			return fmt.Errorf("type %s, fields are only supported in Go", typeName)
 		}
-	} else {
-		if err = g.declareMapAndNameVars(values, typeName); err != nil {
-			return
+		if fs.Mode == ModeEnum {
+			return fmt.Errorf("type %s, the enum mode is only supported in Go", typeName)
 		}
 	}
 
-	if err = templates.Lookup("props.go.tmpl").Execute(&g.buf, map[string]interface{}{
-		"Type":   typeName,
-		"Values": values,
-	}); err != nil {
-		return
-	}
-
-	return
+	return b.Generate(&g.buf, fs)
 }
 
 // splitIntoRuns breaks the values into runs of contiguous sequences.
@@ -172,51 +195,15 @@ func splitIntoRuns(v []Value) [][]Value {
 	return runs
 }
 
-func (g *Generator) declareIndexAndNameVars(runs [][]Value, typeName string) error {
-	var indexes [][]int
-	for i, run := range runs {
-		if len(run) > 1 {
-			if indexes == nil {
-				indexes = make([][]int, len(runs))
-			}
-
-			indexes[i] = make([]int, len(run))
-			off := 0
-			for j, v := range run {
-				off += len(v.OriginalName)
-				indexes[i][j] = off
-			}
+func (g *Generator) Format() []byte {
+	if f, ok := g.backend.(Formatter); ok {
+		src, err := f.Format(g.buf.Bytes())
+		if err != nil {
+			return g.buf.Bytes()
 		}
+		return src
 	}
 
-	return templates.Lookup("index_and_name.go.tmpl").Execute(&g.buf, map[string]interface{}{
-		"Runs":    runs,
-		"Indexes": indexes,
-		"Type":    typeName,
-	})
-}
-
-func (g *Generator) declareMapAndNameVars(values []Value, typeName string) error {
-	type value struct {
-		Value
-		Start, End int
-	}
-
-	_values := make([]value, len(values))
-	off := 0
-
-	for i, v := range values {
-		_values[i] = value{v, off, off + len(v.OriginalName)}
-		off += len(v.OriginalName)
-	}
-
-	return templates.Lookup("map_and_name.go.tmpl").Execute(&g.buf, map[string]interface{}{
-		"Values": _values,
-		"Type":   typeName,
-	})
-}
-
-func (g *Generator) Format() []byte {
 	src, err := format.Source(g.buf.Bytes())
 	if err != nil {
 		return g.buf.Bytes()
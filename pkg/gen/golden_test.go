@@ -2,12 +2,8 @@ package gen
 
 import (
 	"embed"
-	"os"
-	"path/filepath"
 	"strings"
 	"testing"
-
-	"github.com/flier/go-bitflags/internal/testenv"
 )
 
 // Golden represents a test case.
@@ -18,59 +14,64 @@ type Golden struct {
 	typeName    string
 	input       string // input; the package clause is provided when running the test.
 	output      string // expected output.
+	templateDir string // templates overriding or extending the embedded ones.
+	lang        string // output language; Go if empty.
+	json        string // JSON shape of the flags.
 }
 
-//go:embed testdata/*.go
+//go:embed testdata/*.go testdata/*.ts testdata/*.py testdata/*.rs
 var testdata embed.FS
 
 var golden = []Golden{
-	{"pill", "", false, "Pill", "pill_in.go", "pill_out.go"},
+	{"pill", "", false, "Pill", "pill_in.go", "pill_out.go", "", "", ""},
+	{"names", "", false, "Pill", "pill_in.go", "names_out.go", "testdata/templates", "", ""},
+	{"ts", "", false, "Pill", "pill_in.go", "pill_out.ts", "", "ts", ""},
+	{"python", "", false, "Pill", "pill_in.go", "pill_out.py", "", "python", ""},
+	{"rust", "", false, "Pill", "pill_in.go", "pill_out.rs", "", "rust", ""},
+	{"json_array", "", false, "Pill", "pill_in.go", "json_array_out.go", "", "", "array"},
+	{"json_string", "", false, "Pill", "pill_in.go", "json_string_out.go", "", "", "string"},
+	{"attr", "", false, "Perm", "attr_in.go", "attr_out.go", "", "", ""},
+	{"attr_linecomment", "", true, "Perm", "attr_in.go", "attr_linecomment_out.go", "", "", ""},
+	{"deprecated", "", false, "Pill", "deprecated_in.go", "deprecated_out.go", "", "", "array"},
+	{"deprecated_ts", "", false, "Pill", "deprecated_in.go", "deprecated_out.ts", "", "ts", ""},
+	{"deprecated_python", "", false, "Pill", "deprecated_in.go", "deprecated_out.py", "", "python", ""},
+	{"deprecated_rust", "", false, "Pill", "deprecated_in.go", "deprecated_out.rs", "", "rust", ""},
+	{"rules", "", false, "Pill", "rules_in.go", "rules_out.go", "", "", ""},
+	{"fields", "", false, "Mode", "fields_in.go", "fields_out.go", "", "", "string"},
+	{"enum", "Color", false, "Color", "enum_in.go", "enum_out.go", "", "", "string"},
+	{"enum_unsigned", "", false, "Color", "enum_unsigned_in.go", "enum_unsigned_out.go", "", "", ""},
 }
 
-const head = `package test
-
-import (
-	"strconv"
-	"strings"
-)
-
-`
-
 func TestGolden(t *testing.T) {
-	testenv.NeedsTool(t, "go")
-
-	dir := t.TempDir()
 	for _, test := range golden {
 		test := test
 		t.Run(test.name, func(t *testing.T) {
-			g := Generator{
+			opts := Options{
 				TrimPrefix:  test.trimPrefix,
 				LineComment: test.lineComment,
-				logf:        t.Logf,
+				TemplateDir: test.templateDir,
+				Lang:        test.lang,
+				JSON:        test.json,
 			}
 
-			file := test.name + ".go"
-			absFile := filepath.Join(dir, file)
-
 			buf, err := testdata.ReadFile("testdata/" + test.input)
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			if err = os.WriteFile(absFile, buf, 0644); err != nil {
+			src, err := GenerateFromSource(map[string][]byte{test.name + ".go": buf}, []string{test.typeName}, opts)
+			if err != nil {
 				t.Fatal(err)
 			}
 
-			if err = g.ParsePackage([]string{absFile}, nil); err != nil {
-				t.Fatal(err)
+			// Skip the "Code generated" comment, which holds the command line.
+			got := strings.ReplaceAll(string(src), "\r\n", "\n")
+			if i := strings.Index(got, "package "); i >= 0 {
+				got = got[i:]
+			} else {
+				got = got[strings.Index(got, "\n")+1:]
 			}
 
-			if err = g.Generate(test.typeName); err != nil {
-				t.Fatal(err)
-			}
-
-			got := head + strings.ReplaceAll(string(g.Format()), "\r\n", "\n")
-
 			if buf, err = testdata.ReadFile("testdata/" + test.output); err != nil {
 				t.Fatal(err)
 			}
@@ -81,3 +82,111 @@ func TestGolden(t *testing.T) {
 		})
 	}
 }
+
+func TestModes(t *testing.T) {
+	const input = `package test
+
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << iota
+	Aspirin
+)
+
+// Level is a log level.
+//
+//bitflags:mode enum
+type Level uint8
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Fatal Level = 9
+)
+`
+
+	src, err := GenerateFromSource(map[string][]byte{"test.go": []byte(input)}, []string{"Pill", "Level"}, Options{JSON: JSONInt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		"func (i Pill) Contains(f Pill) bool",
+		"func (i Level) IsValid() bool",
+		"case i <= 2:\n\t\treturn _Level_name_0[_Level_index_0[i]:_Level_index_0[i+1]]",
+		"return strconv.AppendUint(nil, uint64(i), 10), nil",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("missing %q in\n%s", s, src)
+		}
+	}
+	if strings.Contains(string(src), "func (i Level) Contains") {
+		t.Errorf("flag methods generated for the enum Level")
+	}
+
+	if _, err = GenerateFromSource(map[string][]byte{"test.go": []byte(input)}, []string{"Pill"}, Options{Mode: ModeEnum}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, opts := range []Options{{Mode: "bits"}, {Lang: "ts"}} {
+		if _, err = GenerateFromSource(map[string][]byte{"test.go": []byte(input)}, []string{"Level"}, opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	buf, err := testdata.ReadFile("testdata/pill_in.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := GenerateFromSource(map[string][]byte{"pill.go": buf}, []string{"Pill"}, Options{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		`bitflags "github.com/flier/go-bitflags"`,
+		"func (i Pill) Apply(expr string) (Pill, error) {\n\treturn bitflags.Apply(i, expr, LookupPill)\n}",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("missing %q in\n%s", s, src)
+		}
+	}
+
+	// The lookup is generated without the Apply method, for bitflags.Compile.
+	if src, err = GenerateFromSource(map[string][]byte{"pill.go": buf}, []string{"Pill"}, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "func LookupPill(name string) (Pill, bool) {") {
+		t.Errorf("missing LookupPill in\n%s", src)
+	}
+	if strings.Contains(string(src), "go-bitflags") || strings.Contains(string(src), ") Apply(") {
+		t.Errorf("unexpected Apply method in\n%s", src)
+	}
+
+	if _, err = GenerateFromSource(map[string][]byte{"pill.go": buf}, []string{"Pill"}, Options{Apply: true, Mode: ModeEnum}); err == nil {
+		t.Errorf("expected an error for the Apply method of an enum")
+	}
+
+	buf, err = testdata.ReadFile("testdata/deprecated_in.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err = GenerateFromSource(map[string][]byte{"pill.go": buf}, []string{"Pill"}, Options{Apply: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []string{
+		"// of the deprecated flags looked up by LookupPill.\nvar PillDeprecationHook func(name, notice string)",
+		"\tcase \"Aspirin\":\n\t\tif PillDeprecationHook != nil {\n\t\t\tPillDeprecationHook(name, \"use Paracetamol instead.\")\n\t\t}\n\t\treturn Paracetamol, true",
+	} {
+		if !strings.Contains(string(src), s) {
+			t.Errorf("missing %q in\n%s", s, src)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package gen
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// ImportFlags loads the package of the import path, and collects the integer constants
+// whose names start with the prefix as the flag set of the named type.
+//
+// The constants are declared as aliases of the originals, named after the PascalCase
+// of their names without the prefix, e.g. syscall.MS_RDONLY becomes Rdonly.
+// The zero and multi-bit constants, such as masks, are not flags and are skipped.
+//
+// The first declared constant of a value is kept, and the others sharing it are declared
+// as aliases, unless one of them is preferred, e.g. syscall.MS_RDONLY over syscall.MS_ASYNC.
+func ImportFlags(path, prefix, typeName string, prefer []string, opts Options) (*FlagSet, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes}, path)
+	if err != nil {
+		return nil, fmt.Errorf("load package, %w", err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages matching %v, %w", len(pkgs), path, ErrTooManyPackages)
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, pkgs[0].Errors[0]
+	}
+
+	return importFlags(pkgs[0].Types, prefix, typeName, prefer, opts)
+}
+
+func importFlags(pkg *types.Package, prefix, typeName string, prefer []string, opts Options) (*FlagSet, error) {
+	fs := &FlagSet{
+		Type:    typeName,
+		Doc:     fmt.Sprintf("%s are the %s* constants of the %s package.", typeName, prefix, pkg.Path()),
+		Options: opts,
+	}
+
+	declared := make(map[string]string)
+	scope := pkg.Scope()
+
+	var consts []*types.Const
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !c.Exported() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if basic, ok := c.Type().Underlying().(*types.Basic); !ok || basic.Info()&types.IsInteger == 0 {
+			continue
+		}
+		consts = append(consts, c)
+	}
+
+	preferred := make(map[string]bool, len(prefer))
+	for _, name := range prefer {
+		preferred[name] = true
+	}
+	// In the order of preference for dedupAliases, then of declaration.
+	sort.SliceStable(consts, func(i, j int) bool {
+		if preferred[consts[i].Name()] != preferred[consts[j].Name()] {
+			return preferred[consts[i].Name()]
+		}
+		return consts[i].Pos() < consts[j].Pos()
+	})
+
+	for _, c := range consts {
+		name := c.Name()
+
+		id := identifier(strings.TrimPrefix(name, prefix), typeName)
+		v, err := newValue(id, c.Val(), false)
+		if err != nil {
+			return nil, err
+		}
+		if v.Value == 0 || v.Value&(v.Value-1) != 0 {
+			continue
+		}
+
+		if other, ok := declared[id]; ok {
+			return nil, fmt.Errorf("%s and %s are both imported as %s", other, name, id)
+		}
+		declared[id] = name
+
+		v.Expr = pkg.Name() + "." + name
+		fs.Values = append(fs.Values, v)
+	}
+
+	if len(fs.Values) == 0 {
+		return nil, fmt.Errorf("no single-bit integer constants with prefix %s in package %s", prefix, pkg.Path())
+	}
+
+	fs.Underlying = underlying(fs.Values)
+	fs.Signed = strings.HasPrefix(fs.Underlying, "int")
+	for i := range fs.Values {
+		fs.Values[i].Signed = fs.Signed
+	}
+
+	dedupAliases(fs)
+
+	return fs, nil
+}
@@ -0,0 +1,75 @@
+package gen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const importInput = `package sys
+
+const (
+	MS_RDONLY  = 0x1
+	MS_NOSUID  = 0x2
+	MS_I_VERSION = 0x4
+	MS_ALIAS   = MS_NOSUID
+	MS_NAME    = "name"
+	MS_NONE    = 0x0
+	MS_MASK    = MS_RDONLY | MS_NOSUID
+	O_RDONLY   = 0x0
+)
+`
+
+func TestImportFlags(t *testing.T) {
+	pkg, err := ParseSource(map[string][]byte{"sys.go": []byte(importInput)}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err := importFlags(pkg.Types, "MS_", "MountFlags", nil, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names, exprs []string
+	for _, v := range fs.Values {
+		names = append(names, v.OriginalName)
+		exprs = append(exprs, v.Expr)
+	}
+	if expected := []string{"Rdonly", "Nosuid", "IVersion"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("got names %v, expected %v", names, expected)
+	}
+	if expected := []string{"sys.MS_RDONLY", "sys.MS_NOSUID", "sys.MS_I_VERSION"}; !reflect.DeepEqual(exprs, expected) {
+		t.Errorf("got expressions %v, expected %v", exprs, expected)
+	}
+	if expected := []Alias{{Name: "Alias", Target: "Nosuid", Expr: "sys.MS_ALIAS"}}; !reflect.DeepEqual(fs.Aliases, expected) {
+		t.Errorf("got aliases %v, expected %v", fs.Aliases, expected)
+	}
+	if fs.Underlying != "uint32" {
+		t.Errorf("got underlying %s", fs.Underlying)
+	}
+
+	src, err := GenerateFlagSets(&Package{Name: "mount", Imports: []string{"example.com/sys"}}, []*FlagSet{fs})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"\t\"example.com/sys\"\n",
+		"type MountFlags uint32\n",
+		"\tRdonly   MountFlags = sys.MS_RDONLY\n",
+		"\tAlias    MountFlags = sys.MS_ALIAS\n",
+		"func (i MountFlags) Rdonly() bool { return i.Contains(Rdonly) }\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("missing %q in\n%s", expected, src)
+		}
+	}
+
+	if fs, err = importFlags(pkg.Types, "MS_", "MountFlags", []string{"MS_ALIAS"}, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if expected := []Alias{{Name: "Nosuid", Target: "Alias", Expr: "sys.MS_NOSUID"}}; !reflect.DeepEqual(fs.Aliases, expected) {
+		t.Errorf("got preferred aliases %v, expected %v", fs.Aliases, expected)
+	}
+}
@@ -0,0 +1,121 @@
+package gen
+
+import (
+	"go/token"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// langTemplates are the templates of the backends of other languages than Go.
+var langTemplates = template.Must(template.New("lang").Funcs(funcs).Funcs(template.FuncMap{
+	"jsdoc":   jsdoc,
+	"pydoc":   pydoc,
+	"rustdoc": rustdoc,
+	"indent":  indent,
+}).ParseFS(content, "templates/*.ts.tmpl", "templates/*.py.tmpl", "templates/*.rs.tmpl"))
+
+// langBackend generates the flag sets in another language, from the header and flags templates of its extension.
+type langBackend struct {
+	ext      string          // The extension of the templates, e.g. "py".
+	keywords map[string]bool // The reserved words of the language, which can't name a flag.
+	// types maps the Go underlying types to those of the language, if any.
+	types map[string]string
+}
+
+// langValue is a value, or an alias, named by an identifier of the language.
+type langValue struct {
+	Value
+	Ident  string // The identifier naming the value in the language.
+	Target string // The identifier of the aliased value, for aliases.
+}
+
+// langMapping is a Mapping of the deprecated values, with their names in the language:
+// the identifiers of the values, or their literals for the languages without typed constants.
+type langMapping struct {
+	Name        string   // The printed name.
+	Flags       []string // The deprecated values.
+	Replacement string   // The replacement, if any.
+}
+
+// langMappings returns the mappings of the deprecated values, named by the function.
+func langMappings(fs *FlagSet, name func(originalName string) string) []langMapping {
+	var mappings []langMapping
+	for _, m := range fs.Mappings() {
+		lm := langMapping{Name: m.Name}
+		for _, flag := range m.Flags {
+			lm.Flags = append(lm.Flags, name(flag))
+		}
+		if m.Replacement != "" {
+			lm.Replacement = name(m.Replacement)
+		}
+		mappings = append(mappings, lm)
+	}
+	return mappings
+}
+
+func (b *langBackend) Header(w io.Writer, pkg *Package) error {
+	return langTemplates.ExecuteTemplate(w, "header."+b.ext+".tmpl", map[string]interface{}{
+		"CmdLine": cmdLine(),
+		"Package": pkg,
+	})
+}
+
+func (b *langBackend) Generate(w io.Writer, fs *FlagSet) error {
+	values := make([]langValue, len(fs.Values))
+	idents := make(map[string]string, len(fs.Values)) // original name => identifier
+
+	for i, v := range fs.Values {
+		values[i] = langValue{Value: v, Ident: b.ident(v.Name, fs.Type)}
+		idents[v.OriginalName] = values[i].Ident
+	}
+
+	aliases := make([]langValue, len(fs.Aliases))
+	for i, a := range fs.Aliases {
+		label := a.Label
+		if label == "" {
+			label = a.Name
+		}
+		aliases[i] = langValue{
+			Value:  Value{OriginalName: a.Name, Name: label, Doc: a.Doc},
+			Ident:  b.ident(label, fs.Type),
+			Target: idents[a.Target],
+		}
+	}
+
+	return langTemplates.ExecuteTemplate(w, "flags."+b.ext+".tmpl", struct {
+		*FlagSet
+		Values   []langValue
+		Aliases  []langValue
+		Mappings []langMapping
+		LangType string
+	}{fs, values, aliases, langMappings(fs, func(name string) string { return idents[name] }), b.types[fs.Underlying]})
+}
+
+// Format keeps the source as is.
+func (b *langBackend) Format(src []byte) ([]byte, error) {
+	return src, nil
+}
+
+// ident returns the name if it is an identifier of the language,
+// or its PascalCase, prefixed by the type name if needed, otherwise.
+func (b *langBackend) ident(name, typeName string) string {
+	if token.IsIdentifier(name) && !b.keywords[name] {
+		return name
+	}
+	if id := identifier(name, typeName); !b.keywords[id] {
+		return id
+	}
+	return typeName + name
+}
+
+// indent prefixes each non-empty line of the text.
+func indent(prefix, text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "")
+}
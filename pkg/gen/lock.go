@@ -0,0 +1,176 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LockFileName returns the name of the lock file for the type, e.g. pill.bitflags.lock.
+func LockFileName(typeName string) string {
+	return strings.ToLower(typeName) + ".bitflags.lock"
+}
+
+// Lock records the name to bit assignments of a flag type,
+// so that a bit can't silently be reused across releases.
+type Lock struct {
+	Type  string      `json:"type"`
+	Flags []LockEntry `json:"flags"`
+}
+
+// LockEntry is a single name to value assignment.
+type LockEntry struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`             // The string representation given by the "go/constant" package.
+	Retired bool   `json:"retired,omitempty"` // The name has been removed from the source.
+}
+
+// ReadLock reads the lock file at path.
+func ReadLock(path string) (*Lock, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(Lock)
+	if err = json.Unmarshal(buf, l); err != nil {
+		return nil, fmt.Errorf("parse lock file %s, %w", path, err)
+	}
+
+	return l, nil
+}
+
+// WriteFile writes the lock file to path.
+func (l *Lock) WriteFile(path string) error {
+	buf, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(buf, '\n'), 0644)
+}
+
+// Update merges the values into the lock.
+//
+// Names which are no longer declared, or have been declared with another value,
+// are kept as retired entries, so that their bits are never reused by accident.
+func (l *Lock) Update(values []Value) {
+	declared := make(map[LockEntry]bool, len(values))
+	for _, v := range values {
+		declared[LockEntry{Name: v.OriginalName, Value: v.Str}] = true
+	}
+
+	locked := make(map[LockEntry]bool, len(l.Flags))
+	for i, e := range l.Flags {
+		key := LockEntry{Name: e.Name, Value: e.Value}
+		locked[key] = true
+		l.Flags[i].Retired = !declared[key]
+	}
+
+	for _, v := range values {
+		if key := (LockEntry{Name: v.OriginalName, Value: v.Str}); !locked[key] {
+			l.Flags = append(l.Flags, key)
+		}
+	}
+
+	sort.SliceStable(l.Flags, func(i, j int) bool { return !l.Flags[i].Retired && l.Flags[j].Retired })
+}
+
+// ViolationKind describes how a declaration conflicts with the lock.
+type ViolationKind int
+
+const (
+	Renumbered ViolationKind = iota // A locked name changed its value.
+	Reassigned                      // A locked bit has been given to a different name.
+	Reused                          // A retired bit has been reused without a //bitflags:retired marker.
+)
+
+func (k ViolationKind) String() string {
+	switch k {
+	case Renumbered:
+		return "renumbered"
+	case Reassigned:
+		return "reassigned"
+	case Reused:
+		return "reused"
+	default:
+		return fmt.Sprintf("ViolationKind(%d)", int(k))
+	}
+}
+
+// Violation is a declaration which conflicts with the lock.
+type Violation struct {
+	Kind   ViolationKind
+	Name   string // The declared name.
+	Value  string // The declared value.
+	Locked string // The locked value for renumbered names, or the locked name otherwise.
+}
+
+func (v Violation) Error() string {
+	switch v.Kind {
+	case Renumbered:
+		return fmt.Sprintf("%s changed value from %s to %s", v.Name, v.Locked, v.Value)
+	case Reassigned:
+		return fmt.Sprintf("value %s reassigned from %s to %s", v.Value, v.Locked, v.Name)
+	default:
+		return fmt.Sprintf("value %s of retired %s reused by %s", v.Value, v.Locked, v.Name)
+	}
+}
+
+// Check returns the declarations which conflict with the lock.
+func (l *Lock) Check(values []Value) (violations []Violation) {
+	declared := make(map[string]string, len(values))
+	for _, v := range values {
+		declared[v.OriginalName] = v.Str
+	}
+
+	active := make(map[string]string) // name => value of the active entries.
+	byValue := make(map[string][]LockEntry)
+	for _, e := range l.Flags {
+		if !e.Retired {
+			active[e.Name] = e.Value
+		}
+		byValue[e.Value] = append(byValue[e.Value], e)
+	}
+
+	for _, v := range values {
+		if locked, ok := active[v.OriginalName]; ok {
+			if locked != v.Str {
+				violations = append(violations, Violation{Renumbered, v.OriginalName, v.Str, locked})
+			}
+			continue
+		}
+
+		entries := byValue[v.Str]
+		if len(entries) == 0 {
+			continue // a new bit
+		}
+
+		var reassigned, retired string
+		alias := false
+		for _, e := range entries {
+			switch {
+			case e.Retired:
+				if e.Name != v.OriginalName && retired == "" {
+					retired = e.Name
+				}
+			case declared[e.Name] == e.Value:
+				alias = true
+			case reassigned == "":
+				reassigned = e.Name
+			}
+		}
+
+		switch {
+		case alias:
+		case reassigned != "":
+			violations = append(violations, Violation{Reassigned, v.OriginalName, v.Str, reassigned})
+		case retired != "" && !v.Retired:
+			violations = append(violations, Violation{Reused, v.OriginalName, v.Str, retired})
+		}
+	}
+
+	return
+}
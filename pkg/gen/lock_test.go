@@ -0,0 +1,52 @@
+package gen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func valuesOf(pairs ...string) (values []Value) {
+	for i := 0; i < len(pairs); i += 2 {
+		values = append(values, Value{OriginalName: pairs[i], Str: pairs[i+1]})
+	}
+	return
+}
+
+func TestLock(t *testing.T) {
+	l := &Lock{Type: "Pill"}
+	l.Update(valuesOf("Placebo", "1", "Aspirin", "2", "Ibuprofen", "4"))
+
+	if v := l.Check(valuesOf("Placebo", "1", "Aspirin", "2", "Ibuprofen", "4", "Alias", "4")); len(v) != 0 {
+		t.Errorf("unexpected violations %v", v)
+	}
+
+	// Aspirin renamed to Paracetamol, Ibuprofen renumbered.
+	declared := valuesOf("Placebo", "1", "Paracetamol", "2", "Ibuprofen", "8")
+	expected := []Violation{
+		{Reassigned, "Paracetamol", "2", "Aspirin"},
+		{Renumbered, "Ibuprofen", "8", "4"},
+	}
+	if v := l.Check(declared); !reflect.DeepEqual(v, expected) {
+		t.Errorf("got %v, expected %v", v, expected)
+	}
+
+	l.Update(declared)
+
+	if v := l.Check(declared); len(v) != 0 {
+		t.Errorf("unexpected violations %v", v)
+	}
+
+	// The retired Ibuprofen bit is reused.
+	declared = append(declared, Value{OriginalName: "Aspirin", Str: "4"})
+	expected = []Violation{
+		{Reused, "Aspirin", "4", "Ibuprofen"},
+	}
+	if v := l.Check(declared); !reflect.DeepEqual(v, expected) {
+		t.Errorf("got %v, expected %v", v, expected)
+	}
+
+	declared[len(declared)-1].Retired = true
+	if v := l.Check(declared); len(v) != 0 {
+		t.Errorf("unexpected violations %v", v)
+	}
+}
@@ -0,0 +1,34 @@
+package gen
+
+import "fmt"
+
+// parseMode sets the mode of the type, from the //bitflags:mode directive or the options,
+// and refuses the directives which only apply to the flags in the enum mode.
+func (fs *FlagSet) parseMode() error {
+	fs.Mode = fs.Options.Mode
+	for _, d := range fs.Directives {
+		if d.Name == "mode" {
+			fs.Mode = d.Args
+		}
+	}
+
+	switch fs.Mode {
+	case "":
+		fs.Mode = ModeFlags
+	case ModeFlags:
+	case ModeEnum:
+		if fs.Options.Apply {
+			return fmt.Errorf("the Apply method in the enum mode")
+		}
+		for _, d := range fs.Directives {
+			switch d.Name {
+			case "field", "exclusive", "requires":
+				return fmt.Errorf("%s directive in the enum mode", d.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", fs.Mode)
+	}
+
+	return nil
+}
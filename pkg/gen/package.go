@@ -1,12 +1,80 @@
 package gen
 
 import (
+	"errors"
+	"fmt"
 	"go/ast"
+	"go/token"
 	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
 )
 
+// Package is a type checked package and its syntax files.
 type Package struct {
-	Name  string
-	Defs  map[*ast.Ident]types.Object
-	Files []*File
+	Name    string
+	Fset    *token.FileSet
+	Types   *types.Package
+	Defs    map[*ast.Ident]types.Object
+	Files   []*File
+	Options Options
+	Imports []string // Extra import paths of the generated file.
+}
+
+var (
+	ErrTooManyPackages = errors.New("too many package")
+	ErrNoFiles         = errors.New("no files")
+)
+
+// LoadMode is the packages.LoadMode required by NewPackage.
+const LoadMode = packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax
+
+// LoadPackage loads the single package constructed from the patterns and tags.
+func LoadPackage(patterns, tags []string, opts Options) (*Package, error) {
+	return loadPackage(&packages.Config{}, patterns, tags, opts)
+}
+
+func loadPackage(cfg *packages.Config, patterns, tags []string, opts Options) (*Package, error) {
+	cfg.Mode = LoadMode
+	cfg.Tests = false
+	cfg.BuildFlags = []string{fmt.Sprintf("-tags=%s", strings.Join(tags, " "))}
+
+	pkgs, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, fmt.Errorf("load package, %w", err)
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%d packages matching %v, %w", len(pkgs), strings.Join(patterns, " "), ErrTooManyPackages)
+	}
+
+	return NewPackage(pkgs[0], opts), nil
+}
+
+// NewPackage wraps a package loaded with at least the LoadMode.
+func NewPackage(pkg *packages.Package, opts Options) *Package {
+	return newPackage(pkg.Fset, pkg.Types, pkg.TypesInfo, pkg.Syntax, opts)
+}
+
+func newPackage(fset *token.FileSet, pkg *types.Package, info *types.Info, syntax []*ast.File, opts Options) *Package {
+	p := &Package{
+		Name:    pkg.Name(),
+		Fset:    fset,
+		Types:   pkg,
+		Defs:    info.Defs,
+		Files:   make([]*File, len(syntax)),
+		Options: opts,
+	}
+
+	for i, file := range syntax {
+		p.Files[i] = &File{
+			File:        file,
+			Package:     p,
+			TrimPrefix:  opts.TrimPrefix,
+			LineComment: opts.LineComment,
+		}
+	}
+
+	return p
 }
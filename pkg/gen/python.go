@@ -0,0 +1,32 @@
+package gen
+
+import "strings"
+
+// NewPythonBackend returns a backend generating a Python module, declaring an enum.IntFlag
+// subclass for each flag set, whose str() matches the Go String method.
+func NewPythonBackend() Backend {
+	return &langBackend{ext: "py", keywords: pythonKeywords}
+}
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+	"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+	// The members of enum.IntFlag.
+	"name": true, "value": true, "mro": true,
+}
+
+// pydoc formats the text as a """ docstring.
+func pydoc(text string) string {
+	text = strings.TrimSpace(text)
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	text = strings.ReplaceAll(text, `"""`, `\"\"\"`)
+
+	if !strings.Contains(text, "\n") {
+		return `"""` + text + `"""`
+	}
+	return `"""` + text + "\n" + `"""`
+}
@@ -0,0 +1,154 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Requirement is a rule of a //bitflags:requires If=>Then directive: the flag If requires the flag Then.
+type Requirement struct {
+	If, Then string // The original names of the flags.
+}
+
+func (r Requirement) String() string {
+	return "requires " + r.If + "=>" + r.Then
+}
+
+// Implication is the flags implied by a flag, directly or not, through the requirements.
+type Implication struct {
+	Flag    string   // The original name of the flag.
+	Implied []string // The original names of the implied flags, sorted.
+}
+
+// HasRules reports whether the flag set has //bitflags:exclusive or //bitflags:requires rules.
+func (fs *FlagSet) HasRules() bool {
+	return len(fs.Exclusive) > 0 || len(fs.Requires) > 0
+}
+
+// parseRules collects the rules of the //bitflags:exclusive A,B,C and //bitflags:requires A=>B,C directives.
+func (fs *FlagSet) parseRules() error {
+	for _, d := range fs.Directives {
+		switch d.Name {
+		case "exclusive":
+			names, err := fs.ruleNames(d.Args)
+			if err != nil {
+				return fmt.Errorf("exclusive %s, %w", d.Args, err)
+			}
+			if len(names) < 2 {
+				return fmt.Errorf("exclusive %s, expected at least 2 flags", d.Args)
+			}
+			fs.Exclusive = append(fs.Exclusive, names)
+
+		case "requires":
+			left, right, ok := strings.Cut(d.Args, "=>")
+			if !ok {
+				return fmt.Errorf("requires %s, expected A=>B", d.Args)
+			}
+			ifs, err := fs.ruleNames(left)
+			if err != nil {
+				return fmt.Errorf("requires %s, %w", d.Args, err)
+			}
+			if len(ifs) != 1 {
+				return fmt.Errorf("requires %s, expected a single flag before =>", d.Args)
+			}
+			thens, err := fs.ruleNames(right)
+			if err != nil {
+				return fmt.Errorf("requires %s, %w", d.Args, err)
+			}
+			for _, then := range thens {
+				if then != ifs[0] {
+					fs.Requires = append(fs.Requires, Requirement{ifs[0], then})
+				}
+			}
+		}
+	}
+
+	return fs.checkRules()
+}
+
+// ruleNames splits the comma-separated list of flag names, which must be values of the flag set.
+func (fs *FlagSet) ruleNames(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if fs.value(name) == nil {
+			return nil, fmt.Errorf("unknown flag %q", name)
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// value returns the value of the original name, or nil.
+func (fs *FlagSet) value(name string) *Value {
+	for i := range fs.Values {
+		if fs.Values[i].OriginalName == name {
+			return &fs.Values[i]
+		}
+	}
+	return nil
+}
+
+// Implications returns the flags implied by each flag requiring others, in declaration order.
+func (fs *FlagSet) Implications() (implications []Implication) {
+	for _, v := range fs.Values {
+		implied := make(map[string]bool)
+		todo := []string{v.OriginalName}
+
+		for len(todo) > 0 {
+			name := todo[0]
+			todo = todo[1:]
+			for _, r := range fs.Requires {
+				if r.If == name && r.Then != v.OriginalName && !implied[r.Then] {
+					implied[r.Then] = true
+					todo = append(todo, r.Then)
+				}
+			}
+		}
+
+		if len(implied) > 0 {
+			names := make([]string, 0, len(implied))
+			for name := range implied {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			implications = append(implications, Implication{v.OriginalName, names})
+		}
+	}
+	return
+}
+
+// checkRules refuses the rules which can't be satisfied: overlapping exclusive flags,
+// and flags requiring, directly or not, exclusive ones.
+func (fs *FlagSet) checkRules() error {
+	for _, group := range fs.Exclusive {
+		for i, a := range group {
+			for _, b := range group[i+1:] {
+				if fs.value(a).Value&fs.value(b).Value != 0 {
+					return fmt.Errorf("exclusive flags %s and %s share bits", a, b)
+				}
+			}
+		}
+	}
+
+	for _, imp := range fs.Implications() {
+		set := append([]string{imp.Flag}, imp.Implied...)
+		for _, group := range fs.Exclusive {
+			var conflicts []string
+			for _, name := range set {
+				for _, member := range group {
+					if name == member {
+						conflicts = append(conflicts, name)
+					}
+				}
+			}
+			if len(conflicts) > 1 {
+				return fmt.Errorf("contradictory rules, %s requires %s which are exclusive",
+					imp.Flag, strings.Join(conflicts, " and "))
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package gen
+
+import "strings"
+
+// NewRustBackend returns a backend generating a Rust module, declaring the flag sets
+// with the bitflags! macro of the bitflags crate, and a Display matching the Go String method.
+func NewRustBackend() Backend {
+	return &langBackend{ext: "rs", keywords: rustKeywords, types: rustTypes}
+}
+
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true, "continue": true,
+	"crate": true, "dyn": true, "else": true, "enum": true, "extern": true, "false": true,
+	"fn": true, "for": true, "if": true, "impl": true, "in": true, "let": true, "loop": true,
+	"match": true, "mod": true, "move": true, "mut": true, "pub": true, "ref": true,
+	"return": true, "self": true, "Self": true, "static": true, "struct": true, "super": true,
+	"trait": true, "true": true, "type": true, "unsafe": true, "use": true, "where": true,
+	"while": true, "abstract": true, "become": true, "box": true, "do": true, "final": true,
+	"macro": true, "override": true, "priv": true, "try": true, "typeof": true, "unsized": true,
+	"virtual": true, "yield": true,
+}
+
+// rustTypes maps the Go integer types to the Rust ones; int and uint are assumed to be 64 bits.
+var rustTypes = map[string]string{
+	"int": "i64", "int8": "i8", "int16": "i16", "int32": "i32", "int64": "i64",
+	"uint": "u64", "uint8": "u8", "uint16": "u16", "uint32": "u32", "uint64": "u64", "uintptr": "usize",
+}
+
+// rustdoc formats the text as a /// comment, one line per line of text.
+func rustdoc(text string) string {
+	return strings.ReplaceAll(comment(text), "//", "///")
+}
@@ -0,0 +1,126 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// SchemaBackend generates a JSON Schema, or an OpenAPI 3 components, document
+// describing the JSON shape of the flag sets.
+type SchemaBackend struct {
+	OpenAPI bool // Whether to generate OpenAPI components rather than JSON Schema definitions.
+}
+
+// Schema is the JSON Schema of a flag type.
+type Schema struct {
+	Type        string       `json:"type,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Items       *Schema      `json:"items,omitempty"`
+	Enum        []string     `json:"enum,omitempty"`
+	AnyOf       []*Schema    `json:"anyOf,omitempty"`
+	UniqueItems bool         `json:"uniqueItems,omitempty"`
+	Pattern     string       `json:"pattern,omitempty"`
+	Minimum     *int         `json:"minimum,omitempty"`
+	Flags       []SchemaFlag `json:"x-flags,omitempty"`
+}
+
+// SchemaFlag documents a flag of the bit table of a schema.
+type SchemaFlag struct {
+	Name        string      `json:"name"`
+	Value       json.Number `json:"value"`
+	Description string      `json:"description,omitempty"`
+}
+
+// schemaUnknown matches the hexadecimal value of the undeclared bits, encoded along the names.
+const schemaUnknown = "0x[0-9a-f]+"
+
+// NewSchema returns the schema of the JSON shape of the flag set.
+func NewSchema(fs *FlagSet) (*Schema, error) {
+	names := make([]string, len(fs.Values))
+	flags := make([]SchemaFlag, len(fs.Values))
+
+	for i, v := range fs.Values {
+		names[i] = v.Name
+		flags[i] = SchemaFlag{Name: v.Name, Value: json.Number(v.Str), Description: v.Doc}
+	}
+
+	s := &Schema{Description: fs.Doc, Flags: flags}
+
+	switch fs.Options.JSON {
+	case JSONArray:
+		s.Type = "array"
+		s.Items = &Schema{Type: "string", AnyOf: []*Schema{{Enum: names}, {Pattern: "^" + schemaUnknown + "$"}}}
+		s.UniqueItems = true
+
+	case JSONString:
+		quoted := make([]string, len(names))
+		for i, name := range names {
+			quoted[i] = regexp.QuoteMeta(name)
+		}
+		name := "(?:" + strings.Join(quoted, "|") + "|" + schemaUnknown + ")"
+
+		s.Type = "string"
+		s.Pattern = "^(?:" + name + `(?:\|` + name + ")*)?$"
+
+	case "", JSONInt:
+		s.Type = "integer"
+		if !fs.Signed {
+			s.Minimum = new(int)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown JSON shape %s", fs.Options.JSON)
+	}
+
+	return s, nil
+}
+
+func (b SchemaBackend) Header(w io.Writer, pkg *Package) (err error) {
+	comment, err := json.Marshal(fmt.Sprintf("Code generated by %q; DO NOT EDIT.", cmdLine()))
+	if err != nil {
+		return
+	}
+
+	if b.OpenAPI {
+		_, err = fmt.Fprintf(w, `{"components": {"x-comment": %s, "schemas": {`, comment)
+	} else {
+		_, err = fmt.Fprintf(w, `{"$schema": "https://json-schema.org/draft/2020-12/schema", "$comment": %s, "$defs": {`, comment)
+	}
+	return
+}
+
+func (b SchemaBackend) Generate(w io.Writer, fs *FlagSet) error {
+	s, err := NewSchema(fs)
+	if err != nil {
+		return err
+	}
+
+	buf, err := json.Marshal(map[string]*Schema{fs.Type: s})
+	if err != nil {
+		return err
+	}
+
+	// Write the member of the definitions, without the braces.
+	_, err = fmt.Fprintf(w, "%s,", buf[1:len(buf)-1])
+	return err
+}
+
+// Format closes the definitions of the document, and indents it.
+func (b SchemaBackend) Format(src []byte) ([]byte, error) {
+	src = append(bytes.TrimSuffix(bytes.TrimSpace(src), []byte(",")), "}}"...)
+	if b.OpenAPI {
+		src = append(src, '}')
+	}
+
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, "", "  "); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
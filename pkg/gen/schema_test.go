@@ -0,0 +1,96 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func pillFlagSet(shape string) *FlagSet {
+	return &FlagSet{
+		Type:       "Pill",
+		Underlying: "uint8",
+		Doc:        "Pill is a set of pills.",
+		Values: []Value{
+			{OriginalName: "Placebo", Name: "Placebo", Value: 1, Str: "1"},
+			{OriginalName: "Aspirin", Name: "Aspirin", Value: 2, Str: "2", Doc: "Aspirin relieves pain."},
+		},
+		Options: Options{JSON: shape},
+	}
+}
+
+func TestSchema(t *testing.T) {
+	flags := []SchemaFlag{{"Placebo", "1", ""}, {"Aspirin", "2", "Aspirin relieves pain."}}
+
+	s, err := NewSchema(pillFlagSet(JSONArray))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != "array" || !s.UniqueItems || !reflect.DeepEqual(s.Items, &Schema{Type: "string", AnyOf: []*Schema{{Enum: []string{"Placebo", "Aspirin"}}, {Pattern: "^0x[0-9a-f]+$"}}}) {
+		t.Errorf("got array schema %+v", s)
+	}
+	if s.Description != "Pill is a set of pills." || !reflect.DeepEqual(s.Flags, flags) {
+		t.Errorf("got description %q, flags %v", s.Description, s.Flags)
+	}
+
+	if s, err = NewSchema(pillFlagSet(JSONString)); err != nil {
+		t.Fatal(err)
+	}
+	re := regexp.MustCompile(s.Pattern)
+	for str, matched := range map[string]bool{"": true, "Aspirin": true, "Placebo|Aspirin": true, "Placebo|0x80": true, "Placebo|": false, "Nope": false, "0x": false} {
+		if re.MatchString(str) != matched {
+			t.Errorf("pattern %s matching %q, expected %v", s.Pattern, str, matched)
+		}
+	}
+
+	if s, err = NewSchema(pillFlagSet("")); err != nil {
+		t.Fatal(err)
+	}
+	if s.Type != "integer" || s.Minimum == nil || *s.Minimum != 0 {
+		t.Errorf("got integer schema %+v", s)
+	}
+
+	if _, err = NewSchema(pillFlagSet("object")); err == nil {
+		t.Error("expected an error for an unknown shape")
+	}
+}
+
+func TestSchemaBackend(t *testing.T) {
+	for _, test := range []struct {
+		backend SchemaBackend
+		path    []string
+	}{
+		{SchemaBackend{}, []string{"$defs"}},
+		{SchemaBackend{OpenAPI: true}, []string{"components", "schemas"}},
+	} {
+		var buf bytes.Buffer
+		if err := test.backend.Header(&buf, &Package{}); err != nil {
+			t.Fatal(err)
+		}
+		for _, shape := range []string{JSONArray, JSONInt} {
+			fs := pillFlagSet(shape)
+			fs.Type += shape
+			if err := test.backend.Generate(&buf, fs); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		src, err := test.backend.Format(buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var doc map[string]interface{}
+		if err = json.Unmarshal(src, &doc); err != nil {
+			t.Fatalf("%v\n%s", err, src)
+		}
+		for _, key := range test.path {
+			doc = doc[key].(map[string]interface{})
+		}
+		if len(doc) != 2 || doc["Pillarray"] == nil || doc["Pillint"] == nil {
+			t.Errorf("got definitions %v", doc)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package gen
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// TypeErrors are the type errors of the files given to ParseSource.
+type TypeErrors []error
+
+func (e TypeErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ParseSource parses and type checks the single package made of the files,
+// keyed by their names, without invoking the go command.
+//
+// Imports are type checked from the source of GOROOT or GOPATH.
+// The files must type check, including the methods of the generated file they use,
+// or ParseSource returns their TypeErrors.
+func ParseSource(files map[string][]byte, opts Options) (*Package, error) {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	syntax := make([]*ast.File, len(names))
+	for i, name := range names {
+		f, err := parser.ParseFile(fset, name, files[name], parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		syntax[i] = f
+	}
+
+	if len(syntax) == 0 {
+		return nil, ErrNoFiles
+	}
+
+	var errs TypeErrors
+	cfg := &types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) { errs = append(errs, err) },
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+	}
+
+	pkg, _ := cfg.Check(syntax[0].Name.Name, fset, syntax, info)
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return newPackage(fset, pkg, info, syntax, opts), nil
+}
+
+// GenerateFromSource generates the methods of the named types declared in the files,
+// keyed by their names, without invoking the go command.
+func GenerateFromSource(files map[string][]byte, typeNames []string, opts Options) ([]byte, error) {
+	pkg, err := ParseSource(files, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Generator{pkg: pkg, Options: opts}
+
+	if err = g.GenerateHeader(); err != nil {
+		return nil, err
+	}
+
+	for _, typeName := range typeNames {
+		if err = g.Generate(typeName); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.Format(), nil
+}
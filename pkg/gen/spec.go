@@ -0,0 +1,232 @@
+package gen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is a declarative definition of flag types, shared with non-Go consumers.
+//
+// For example, in YAML
+//
+//	package: painkiller
+//	types:
+//	  - name: Pill
+//	    type: uint8
+//	    doc: Pill is a set of pills.
+//	    flags:
+//	      - name: Placebo
+//	        bit: 0
+//	      - name: Aspirin
+//	        bit: 1
+//	        doc: Aspirin relieves pain.
+//	      - name: Paracetamol
+//	        value: 4
+//	      - name: Acetaminophen
+//	        alias: Paracetamol
+//	      - name: Ibuprofen
+//	        bit: 3
+//	        deprecated: use Paracetamol
+type Spec struct {
+	Package string     `json:"package,omitempty" yaml:"package,omitempty"` // Package name of the generated file.
+	Types   []SpecType `json:"types" yaml:"types"`
+}
+
+// SpecType is the definition of a flag type.
+type SpecType struct {
+	Name  string     `json:"name" yaml:"name"`
+	Type  string     `json:"type,omitempty" yaml:"type,omitempty"` // The underlying integer type; uint by default.
+	Doc   string     `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Flags []SpecFlag `json:"flags" yaml:"flags"`
+}
+
+// SpecFlag is the definition of a flag, with exactly one of Bit, Value or Alias.
+type SpecFlag struct {
+	Name       string  `json:"name" yaml:"name"`
+	Bit        *uint   `json:"bit,omitempty" yaml:"bit,omitempty"`     // The flag is 1 << Bit.
+	Value      *uint64 `json:"value,omitempty" yaml:"value,omitempty"` // The flag value, possibly of many bits.
+	Alias      string  `json:"alias,omitempty" yaml:"alias,omitempty"` // The flag is another name of the named flag.
+	Doc        string  `json:"doc,omitempty" yaml:"doc,omitempty"`
+	Deprecated string  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"` // The deprecation notice.
+}
+
+// ReadSpec reads a JSON, or YAML, spec file.
+func ReadSpec(path string) (*Spec, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := new(Spec)
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(buf))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(spec)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(buf))
+		dec.KnownFields(true)
+		err = dec.Decode(spec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse spec %s, %w", path, err)
+	}
+
+	return spec, nil
+}
+
+// underlyingBits is the size of the integer types; int and uint are assumed to be 64 bits.
+var underlyingBits = map[string]uint{
+	"int": 64, "int8": 8, "int16": 16, "int32": 32, "int64": 64,
+	"uint": 64, "uint8": 8, "uint16": 16, "uint32": 32, "uint64": 64, "uintptr": 64,
+}
+
+// FlagSets validates the spec and returns the flag sets it declares.
+func (s *Spec) FlagSets(opts Options) ([]*FlagSet, error) {
+	sets := make([]*FlagSet, 0, len(s.Types))
+	types := make(map[string]bool, len(s.Types))
+
+	for _, t := range s.Types {
+		if !token.IsIdentifier(t.Name) {
+			return nil, fmt.Errorf("invalid type name %q", t.Name)
+		}
+		if types[t.Name] {
+			return nil, fmt.Errorf("duplicate type %s", t.Name)
+		}
+		types[t.Name] = true
+
+		fs, err := t.flagSet(opts)
+		if err != nil {
+			return nil, fmt.Errorf("type %s, %w", t.Name, err)
+		}
+		fs.Package = s.Package
+		sets = append(sets, fs)
+	}
+
+	return sets, nil
+}
+
+func (t *SpecType) flagSet(opts Options) (*FlagSet, error) {
+	fs := &FlagSet{
+		Type:       t.Name,
+		Underlying: t.Type,
+		Doc:        t.Doc,
+		Options:    opts,
+	}
+	if fs.Underlying == "" {
+		fs.Underlying = "uint"
+	}
+
+	size, ok := underlyingBits[fs.Underlying]
+	if !ok {
+		return nil, fmt.Errorf("invalid underlying type %s", fs.Underlying)
+	}
+	fs.Signed = !strings.HasPrefix(fs.Underlying, "u")
+	if fs.Signed {
+		size-- // Keep clear of the sign bit.
+	}
+
+	names := make(map[string]bool, len(t.Flags))
+	values := make(map[uint64]string, len(t.Flags)) // value => name
+
+	for _, f := range t.Flags {
+		if !token.IsIdentifier(f.Name) {
+			return nil, fmt.Errorf("invalid flag name %q", f.Name)
+		}
+		if names[f.Name] {
+			return nil, fmt.Errorf("duplicate flag %s", f.Name)
+		}
+		names[f.Name] = true
+	}
+
+	for _, f := range t.Flags {
+		doc := f.Doc
+		if f.Deprecated != "" {
+			if doc != "" {
+				doc += "\n\n"
+			}
+			doc += "Deprecated: " + f.Deprecated
+		}
+
+		switch {
+		case f.Alias != "" && f.Bit == nil && f.Value == nil:
+			if !names[f.Alias] {
+				return nil, fmt.Errorf("flag %s is an alias of unknown flag %s", f.Name, f.Alias)
+			}
+			fs.Aliases = append(fs.Aliases, Alias{Name: f.Name, Target: f.Alias, Doc: doc})
+			continue
+
+		case f.Bit != nil && f.Value == nil && f.Alias == "":
+			if *f.Bit >= size {
+				return nil, fmt.Errorf("bit %d of flag %s overflows %s", *f.Bit, f.Name, fs.Underlying)
+			}
+
+		case f.Value != nil && f.Bit == nil && f.Alias == "":
+			if *f.Value == 0 {
+				return nil, fmt.Errorf("value of flag %s is zero, which every value contains", f.Name)
+			}
+			if size < 64 && *f.Value >= 1<<size {
+				return nil, fmt.Errorf("value %d of flag %s overflows %s", *f.Value, f.Name, fs.Underlying)
+			}
+
+		default:
+			return nil, fmt.Errorf("flag %s must have exactly one of bit, value or alias", f.Name)
+		}
+
+		v := Value{
+			OriginalName: f.Name,
+			Name:         f.Name,
+			Signed:       fs.Signed,
+			Doc:          doc,
+			Deprecated:   f.Deprecated,
+		}
+		if f.Bit != nil {
+			v.Value = 1 << *f.Bit
+			v.Expr = "1 << " + strconv.FormatUint(uint64(*f.Bit), 10)
+		} else {
+			v.Value = *f.Value
+		}
+		v.Str = strconv.FormatUint(v.Value, 10)
+
+		if other, ok := values[v.Value]; ok {
+			return nil, fmt.Errorf("flags %s and %s have the same value %s, declare an alias instead", other, f.Name, v.Str)
+		}
+		values[v.Value] = f.Name
+
+		fs.Values = append(fs.Values, v)
+	}
+
+	for _, a := range fs.Aliases {
+		if !declaredValue(fs.Values, a.Target) {
+			return nil, fmt.Errorf("flag %s is an alias of alias %s", a.Name, a.Target)
+		}
+	}
+
+	if len(fs.Values) == 0 {
+		return nil, fmt.Errorf("no flags defined")
+	}
+
+	if err := fs.resolveReplacements(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
+}
+
+// declaredValue reports whether the name is declared as a value, rather than an alias.
+func declaredValue(values []Value, name string) bool {
+	for _, v := range values {
+		if v.OriginalName == name {
+			return true
+		}
+	}
+	return false
+}
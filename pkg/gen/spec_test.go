@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSpec(t *testing.T) {
+	spec, err := ReadSpec("testdata/pill_spec.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sets, err := spec.FlagSets(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := GenerateFlagSets(&Package{Name: spec.Package}, sets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Skip the "Code generated" comment, which holds the command line.
+	got := string(src[strings.Index(string(src), "package "):])
+
+	buf, err := testdata.ReadFile("testdata/spec_out.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := strings.ReplaceAll(string(buf), "\r\n", "\n"); got != expected {
+		t.Errorf("got(%d)\n====\n%q\n====\nexpected(%d)\n====\n%q", len(got), got, len(expected), expected)
+	}
+}
+
+func TestInvalidSpec(t *testing.T) {
+	for _, test := range []struct {
+		name, spec, err string
+	}{
+		{"duplicate name", `{"types": [{"name": "T", "flags": [{"name": "A", "bit": 0}, {"name": "A", "bit": 1}]}]}`, "duplicate flag A"},
+		{"duplicate bit", `{"types": [{"name": "T", "flags": [{"name": "A", "bit": 0}, {"name": "B", "value": 1}]}]}`, "flags A and B have the same value 1"},
+		{"zero", `{"types": [{"name": "T", "flags": [{"name": "None", "value": 0}, {"name": "A", "bit": 0}]}]}`, "value of flag None is zero"},
+		{"overflow", `{"types": [{"name": "T", "type": "int8", "flags": [{"name": "A", "bit": 7}]}]}`, "bit 7 of flag A overflows int8"},
+		{"unknown alias", `{"types": [{"name": "T", "flags": [{"name": "A", "bit": 0}, {"name": "B", "alias": "C"}]}]}`, "alias of unknown flag C"},
+		{"ambiguous", `{"types": [{"name": "T", "flags": [{"name": "A", "bit": 0, "value": 1}]}]}`, "exactly one of bit, value or alias"},
+		{"unknown field", `{"types": [{"name": "T", "flags": [{"name": "A", "bits": 0}]}]}`, "unknown field"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "spec.json")
+			if err := os.WriteFile(path, []byte(test.spec), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			spec, err := ReadSpec(path)
+			if err == nil {
+				_, err = spec.FlagSets(Options{})
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %v, expected %q", err, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package gen
+
+import (
+	"strings"
+	"unicode"
+)
+
+// words splits a name into its words, e.g. "HTTPServer_Name" into "HTTP", "Server", "Name".
+func words(s string) (words []string) {
+	runes := []rune(s)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start >= 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && next) {
+				words = append(words, string(runes[start:i]))
+				start = i
+			}
+		}
+
+		if start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return
+}
+
+// title upper-cases the first letter of s.
+func title(s string) string {
+	for i, r := range s {
+		return string(unicode.ToUpper(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// untitle lower-cases the first letter of s.
+func untitle(s string) string {
+	for i, r := range s {
+		return string(unicode.ToLower(r)) + s[i+len(string(r)):]
+	}
+	return s
+}
+
+// pascal converts s to PascalCase, e.g. "MS_RDONLY" to "MsRdonly".
+func pascal(s string) string {
+	var b strings.Builder
+	for _, w := range words(s) {
+		b.WriteString(title(strings.ToLower(w)))
+	}
+	return b.String()
+}
+
+// camel converts s to camelCase, e.g. "MS_RDONLY" to "msRdonly".
+func camel(s string) string {
+	return untitle(pascal(s))
+}
+
+// snake converts s to snake_case, e.g. "ReadOnly" to "read_only".
+func snake(s string) string {
+	return strings.ToLower(strings.Join(words(s), "_"))
+}
+
+// kebab converts s to kebab-case, e.g. "ReadOnly" to "read-only".
+func kebab(s string) string {
+	return strings.ToLower(strings.Join(words(s), "-"))
+}
+
+// screaming converts s to SCREAMING_SNAKE_CASE, e.g. "ReadOnly" to "READ_ONLY".
+func screaming(s string) string {
+	return strings.ToUpper(strings.Join(words(s), "_"))
+}
+
+// comment formats the text as a // comment, one line per line of text.
+func comment(text string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString("//")
+		if line != "" {
+			b.WriteString(" " + line)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,31 @@
+package gen
+
+import "testing"
+
+func TestStrCase(t *testing.T) {
+	for _, test := range []struct {
+		s                                      string
+		pascal, camel, snake, kebab, screaming string
+	}{
+		{"MS_RDONLY", "MsRdonly", "msRdonly", "ms_rdonly", "ms-rdonly", "MS_RDONLY"},
+		{"ReadOnly", "ReadOnly", "readOnly", "read_only", "read-only", "READ_ONLY"},
+		{"HTTPServer2Name", "HttpServer2Name", "httpServer2Name", "http_server2_name", "http-server2-name", "HTTP_SERVER2_NAME"},
+		{"placebo", "Placebo", "placebo", "placebo", "placebo", "PLACEBO"},
+	} {
+		for _, c := range []struct {
+			name     string
+			f        func(string) string
+			expected string
+		}{
+			{"pascal", pascal, test.pascal},
+			{"camel", camel, test.camel},
+			{"snake", snake, test.snake},
+			{"kebab", kebab, test.kebab},
+			{"screaming", screaming, test.screaming},
+		} {
+			if got := c.f(test.s); got != c.expected {
+				t.Errorf("%s(%q) = %q, expected %q", c.name, test.s, got, c.expected)
+			}
+		}
+	}
+}
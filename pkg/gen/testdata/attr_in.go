@@ -0,0 +1,15 @@
+package test
+
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	//bitflags:attr group=files
+	Write
+	//bitflags:attr admin
+	//bitflags:attr group=files
+	//bitflags:attr since="v2.3"
+	Delete
+	Grant //bitflags:attr admin
+	Chown // ChangeOwner
+)
@@ -0,0 +1,188 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Read-1]
+	_ = x[Write-2]
+	_ = x[Delete-4]
+	_ = x[Grant-8]
+	_ = x[Chown-16]
+}
+
+const (
+	_Perm_name_0 = "ReadWrite"
+	_Perm_name_1 = "Delete"
+	_Perm_name_2 = "Grant"
+	_Perm_name_3 = "Chown"
+)
+
+var (
+	_Perm_index_0 = [...]uint{0, 4, 9}
+)
+
+func (i Perm) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Perm_name_0[_Perm_index_0[i]:_Perm_index_0[i+1]]
+	case i == 4:
+		return _Perm_name_1
+	case i == 8:
+		return _Perm_name_2
+	case i == 16:
+		return _Perm_name_3
+	default:
+		return "Perm(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Perm) Contains(f Perm) bool { return (i & f) == f }
+
+func (i Perm) Read() bool { return i.Contains(Read) }
+
+func (i Perm) Write() bool { return i.Contains(Write) }
+
+func (i Perm) Delete() bool { return i.Contains(Delete) }
+
+func (i Perm) Grant() bool { return i.Contains(Grant) }
+
+func (i Perm) Chown() bool { return i.Contains(Chown) }
+
+func (i Perm) String() string {
+	var b strings.Builder
+
+	if i.Read() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Read")
+	}
+
+	if i.Write() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Write")
+	}
+
+	if i.Delete() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Delete")
+	}
+
+	if i.Grant() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Grant")
+	}
+
+	if i.Chown() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Chown")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Perm) Names() []string {
+	var names []string
+	if i.Read() {
+		names = append(names, "Read")
+	}
+	if i.Write() {
+		names = append(names, "Write")
+	}
+	if i.Delete() {
+		names = append(names, "Delete")
+	}
+	if i.Grant() {
+		names = append(names, "Grant")
+	}
+	if i.Chown() {
+		names = append(names, "Chown")
+	}
+	return names
+}
+
+// LookupPerm returns the flag of the name, as printed by the String method.
+func LookupPerm(name string) (Perm, bool) {
+	switch name {
+	case "Read":
+		return Read, true
+	case "Write":
+		return Write, true
+	case "Delete":
+		return Delete, true
+	case "Grant":
+		return Grant, true
+	case "Chown":
+		return Chown, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Read.
+func (i Perm) Description() string {
+	switch i {
+	case Chown:
+		return "ChangeOwner"
+	}
+	return ""
+}
+
+// PermHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PermHelp() [][2]string {
+	return [][2]string{
+		{"Read", ""},
+		{"Write", ""},
+		{"Delete", ""},
+		{"Grant", ""},
+		{"Chown", "ChangeOwner"},
+	}
+}
+
+var _Perm_attrs = [...]struct {
+	flag       Perm
+	key, value string
+}{
+	{Write, "group", "files"},
+	{Delete, "admin", "true"},
+	{Delete, "group", "files"},
+	{Delete, "since", "v2.3"},
+	{Grant, "admin", "true"},
+}
+
+// Attr returns the value of the //bitflags:attr directive of a single flag with the key.
+func (i Perm) Attr(key string) (string, bool) {
+	for _, a := range _Perm_attrs {
+		if a.flag == i && a.key == key {
+			return a.value, true
+		}
+	}
+	return "", false
+}
+
+// PermWithAttr returns the flags whose //bitflags:attr directive sets the key to the value.
+func PermWithAttr(key, value string) Perm {
+	var mask Perm
+	for _, a := range _Perm_attrs {
+		if a.key == key && a.value == value {
+			mask |= a.flag
+		}
+	}
+	return mask
+}
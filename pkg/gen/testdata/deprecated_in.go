@@ -0,0 +1,13 @@
+package test
+
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << iota
+	// Aspirin relieves pain.
+	//
+	// Deprecated: use Paracetamol instead.
+	Aspirin
+	Paracetamol
+	Ibuprofen // Deprecated: no longer sold.
+)
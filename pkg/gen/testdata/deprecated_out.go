@@ -0,0 +1,252 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Placebo-1]
+	_ = x[Aspirin-2]
+	_ = x[Paracetamol-4]
+	_ = x[Ibuprofen-8]
+}
+
+const (
+	_Pill_name_0 = "PlaceboAspirin"
+	_Pill_name_1 = "Paracetamol"
+	_Pill_name_2 = "Ibuprofen"
+)
+
+var (
+	_Pill_index_0 = [...]uint{0, 7, 14}
+)
+
+func (i Pill) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
+	case i == 4:
+		return _Pill_name_1
+	case i == 8:
+		return _Pill_name_2
+	default:
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Pill) Contains(f Pill) bool { return (i & f) == f }
+
+func (i Pill) Placebo() bool { return i.Contains(Placebo) }
+
+func (i Pill) Aspirin() bool { return i.Contains(Aspirin) }
+
+func (i Pill) Paracetamol() bool { return i.Contains(Paracetamol) }
+
+func (i Pill) Ibuprofen() bool { return i.Contains(Ibuprofen) }
+
+func (i Pill) String() string {
+	var b strings.Builder
+
+	if i.Placebo() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Placebo")
+	}
+
+	if i.Paracetamol() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	if i.Contains(Aspirin) && !i.Contains(Paracetamol) {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	if i.Contains(Ibuprofen) {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Ibuprofen")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	if i.Contains(Aspirin) && !i.Contains(Paracetamol) {
+		names = append(names, "Paracetamol")
+	}
+	if i.Contains(Ibuprofen) {
+		names = append(names, "Ibuprofen")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method,
+// or the replacement of a deprecated one.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Paracetamol":
+		return Paracetamol, true
+	case "Aspirin":
+		if PillDeprecationHook != nil {
+			PillDeprecationHook(name, "use Paracetamol instead.")
+		}
+		return Paracetamol, true
+	case "Ibuprofen":
+		if PillDeprecationHook != nil {
+			PillDeprecationHook(name, "no longer sold.")
+		}
+		return Ibuprofen, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	switch i {
+	case Aspirin:
+		return "Aspirin relieves pain. Deprecated: use Paracetamol instead."
+	case Ibuprofen:
+		return "Deprecated: no longer sold."
+	}
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Paracetamol", ""},
+	}
+}
+
+// PillDeprecationHook is called, if not nil, with the name and the deprecation notice
+// of the deprecated flags looked up by LookupPill, or parsed.
+var PillDeprecationHook func(name, notice string)
+
+var _Pill_deprecated = map[string]struct {
+	flag, replacement Pill
+	notice            string
+}{
+	"Aspirin":   {Aspirin, Paracetamol, "use Paracetamol instead."},
+	"Ibuprofen": {Ibuprofen, Ibuprofen, "no longer sold."},
+}
+
+// IsDeprecated returns the deprecation notice of a single deprecated flag.
+func (i Pill) IsDeprecated() (string, bool) {
+	for _, d := range _Pill_deprecated {
+		if d.flag == i {
+			return d.notice, true
+		}
+	}
+	return "", false
+}
+
+var _Pill_flags = map[string]Pill{
+	"Placebo":     Placebo,
+	"Paracetamol": Paracetamol,
+}
+
+// _Pill_known is the bits of the constants, the others being encoded in hexadecimal, e.g. "0x80".
+const _Pill_known = Placebo | Aspirin | Paracetamol | Ibuprofen
+
+// _PillUnknown returns the hexadecimal value of the bits of no constant, if any.
+func _PillUnknown(i Pill) (string, bool) {
+	if rest := i &^ _Pill_known; rest != 0 {
+		return "0x" + strconv.FormatUint(uint64(rest), 16), true
+	}
+	return "", false
+}
+
+// _PillFlag returns the flag of the name, or the replacement of a deprecated one,
+// or the undeclared bits of a hexadecimal value.
+func _PillFlag(name string) (Pill, error) {
+	if f, ok := _Pill_flags[name]; ok {
+		return f, nil
+	}
+	if d, ok := _Pill_deprecated[name]; ok {
+		if PillDeprecationHook != nil {
+			PillDeprecationHook(name, d.notice)
+		}
+		return d.replacement, nil
+	}
+	if strings.HasPrefix(name, "0x") {
+		if v, err := strconv.ParseUint(name[2:], 16, 64); err == nil && v != 0 && uint64(uint8(v)) == v {
+			if f := Pill(v); f&_Pill_known == 0 {
+				return f, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid Pill flag %q", name)
+}
+
+// ParsePill returns the flags named in s, as formatted by the String method, e.g. "Placebo|Paracetamol".
+func ParsePill(s string) (Pill, error) {
+	var i Pill
+	if s == "" {
+		return i, nil
+	}
+	for _, name := range strings.Split(s, "|") {
+		f, err := _PillFlag(name)
+		if err != nil {
+			return 0, err
+		}
+		i |= f
+	}
+	return i, nil
+}
+
+// MarshalJSON encodes the flags as an array of the names of the flags set,
+// and of the hexadecimal value of the undeclared bits, if any.
+func (i Pill) MarshalJSON() ([]byte, error) {
+	names := i.Names()
+	if names == nil {
+		names = []string{}
+	}
+	if rest, ok := _PillUnknown(i); ok {
+		names = append(names, rest)
+	}
+	return json.Marshal(names)
+}
+
+// UnmarshalJSON decodes the flags from an array of their names.
+func (i *Pill) UnmarshalJSON(b []byte) error {
+	var names []string
+	if err := json.Unmarshal(b, &names); err != nil {
+		return err
+	}
+	*i = 0
+	for _, name := range names {
+		f, err := _PillFlag(name)
+		if err != nil {
+			return err
+		}
+		*i |= f
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+
+import enum
+
+
+class Pill(enum.IntFlag):
+    Placebo = 1
+    Aspirin = 2
+    """Aspirin relieves pain.
+
+    Deprecated: use Paracetamol instead.
+    """
+    Paracetamol = 4
+    Ibuprofen = 8
+
+    def __str__(self):
+        """Returns the names of the flags set joined by "|", like the Go String method."""
+        names = [name for name, flag in _Pill_flags if self & flag == flag]
+        names.extend(
+            name
+            for name, flags, replacement in _Pill_mappings
+            if any(self & flag == flag for flag in flags) and (replacement is None or self & replacement != replacement)
+        )
+        return "|".join(names)
+
+
+_Pill_flags = (
+    ("Placebo", Pill.Placebo),
+    ("Paracetamol", Pill.Paracetamol),
+)
+
+# The names printed for the deprecated flags set: of their replacement, unless set too, or their own.
+_Pill_mappings = (
+    ("Paracetamol", (Pill.Aspirin,), Pill.Paracetamol),
+    ("Ibuprofen", (Pill.Ibuprofen,), None),
+)
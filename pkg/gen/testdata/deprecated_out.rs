@@ -0,0 +1,52 @@
+
+use bitflags::bitflags;
+
+bitflags! {
+    #[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash)]
+    pub struct Pill: u8 {
+        #[allow(non_upper_case_globals)]
+        const Placebo = 1;
+        /// Aspirin relieves pain.
+        ///
+        /// Deprecated: use Paracetamol instead.
+        #[allow(non_upper_case_globals)]
+        const Aspirin = 2;
+        #[allow(non_upper_case_globals)]
+        const Paracetamol = 4;
+        #[allow(non_upper_case_globals)]
+        const Ibuprofen = 8;
+    }
+}
+
+impl ::core::fmt::Display for Pill {
+    /// Writes the names of the flags set joined by "|", like the Go String method.
+    fn fmt(&self, f: &mut ::core::fmt::Formatter<'_>) -> ::core::fmt::Result {
+        let mut first = true;
+        for (name, flag) in [
+            ("Placebo", Self::Placebo),
+            ("Paracetamol", Self::Paracetamol),
+        ] {
+            if self.contains(flag) {
+                if !first {
+                    f.write_str("|")?;
+                }
+                f.write_str(name)?;
+                first = false;
+            }
+        }
+        // The deprecated flags set are written as their replacement, unless set too, or as themselves.
+        for (name, flags, replacement) in [
+            ("Paracetamol", &[Self::Aspirin][..], Some(Self::Paracetamol)),
+            ("Ibuprofen", &[Self::Ibuprofen][..], None),
+        ] {
+            if flags.iter().any(|&flag| self.contains(flag)) && replacement.map_or(true, |r| !self.contains(r)) {
+                if !first {
+                    f.write_str("|")?;
+                }
+                f.write_str(name)?;
+                first = false;
+            }
+        }
+        Ok(())
+    }
+}
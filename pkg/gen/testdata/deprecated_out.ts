@@ -0,0 +1,68 @@
+
+export type Pill = number;
+
+export const Pill = Object.freeze({
+  Placebo: 1,
+  /**
+   * Aspirin relieves pain.
+   *
+   * @deprecated use Paracetamol instead.
+   */
+  Aspirin: 2,
+  Paracetamol: 4,
+  Ibuprofen: 8,
+} as const);
+
+const _Pill_flags: readonly (readonly [string, Pill])[] = [
+  ["Placebo", 1],
+  ["Paracetamol", 4],
+];
+
+/** The deprecated flags accepted by parsePill, with their replacement. */
+const _Pill_deprecated: readonly (readonly [string, Pill])[] = [
+  ["Aspirin", 4],
+  ["Ibuprofen", 8],
+];
+
+/** The names formatted for the deprecated flags set: of their replacement, unless set too, or their own. */
+const _Pill_mappings: readonly (readonly [string, readonly Pill[], Pill | null])[] = [
+  ["Paracetamol", [2], 4],
+  ["Ibuprofen", [8], null],
+];
+
+/** hasPill reports whether all the flags of f are set in v, like the Go Contains method. */
+export function hasPill(v: Pill, f: Pill): boolean {
+  return (v & f) === f;
+}
+
+/** formatPill returns the names of the flags set in v joined by "|", like the Go String method. */
+export function formatPill(v: Pill): string {
+  const names: string[] = [];
+  for (const [name, f] of _Pill_flags) {
+    if (hasPill(v, f)) {
+      names.push(name);
+    }
+  }
+  for (const [name, flags, replacement] of _Pill_mappings) {
+    if (flags.some((f) => hasPill(v, f)) && (replacement === null || !hasPill(v, replacement))) {
+      names.push(name);
+    }
+  }
+  return names.join("|");
+}
+
+/** parsePill returns the flags named in s, as formatted by formatPill. */
+export function parsePill(s: string): Pill {
+  let v: Pill = 0;
+  if (s === "") {
+    return v;
+  }
+  for (const name of s.split("|")) {
+    const flag = _Pill_flags.find(([n]) => n === name) ?? _Pill_deprecated.find(([n]) => n === name);
+    if (flag === undefined) {
+      throw new Error(`invalid Pill flag "${name}"`);
+    }
+    v |= flag[1];
+  }
+  return v;
+}
@@ -0,0 +1,16 @@
+package test
+
+// Color is a color.
+//
+//bitflags:mode enum
+type Color int
+
+const (
+	ColorNone Color = iota - 1
+	// ColorRed is the color of the blood.
+	ColorRed
+	ColorGreen
+	ColorBlue
+	ColorScarlet Color = 0
+	ColorPurple  Color = 10
+)
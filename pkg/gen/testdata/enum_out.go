@@ -0,0 +1,145 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[ColorNone - -1]
+	_ = x[ColorRed-0]
+	_ = x[ColorGreen-1]
+	_ = x[ColorBlue-2]
+	_ = x[ColorScarlet-0]
+	_ = x[ColorPurple-10]
+}
+
+const (
+	_Color_name_0 = "NoneRedGreenBlue"
+	_Color_name_1 = "Purple"
+)
+
+var (
+	_Color_index_0 = [...]uint{0, 4, 7, 12, 16}
+)
+
+func (i Color) Name() string {
+	switch {
+	case -1 <= i && i <= 2:
+		i -= -1
+		return _Color_name_0[_Color_index_0[i]:_Color_index_0[i+1]]
+	case i == 10:
+		return _Color_name_1
+	default:
+		return "Color(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+// Description returns the doc comment of a single value, e.g. None.
+func (i Color) Description() string {
+	switch i {
+	case ColorRed:
+		return "ColorRed is the color of the blood."
+	}
+	return ""
+}
+
+// ColorHelp returns the names of the values, as printed by the String method, paired with their descriptions.
+func ColorHelp() [][2]string {
+	return [][2]string{
+		{"None", ""},
+		{"Red", "ColorRed is the color of the blood."},
+		{"Green", ""},
+		{"Blue", ""},
+		{"Scarlet", ""},
+		{"Purple", ""},
+	}
+}
+
+func (i Color) String() string { return i.Name() }
+
+var _Color_values = []Color{ColorNone, ColorRed, ColorGreen, ColorBlue, ColorPurple}
+
+// ColorValues returns the values of Color, in increasing order, without duplicates.
+func ColorValues() []Color {
+	return append([]Color(nil), _Color_values...)
+}
+
+// IsValid reports whether the value is one of the declared constants.
+func (i Color) IsValid() bool {
+	switch i {
+	case ColorNone, ColorRed, ColorGreen, ColorBlue, ColorPurple:
+		return true
+	}
+	return false
+}
+
+var _Color_names = map[string]Color{
+	"None":    ColorNone,
+	"Red":     ColorRed,
+	"Green":   ColorGreen,
+	"Blue":    ColorBlue,
+	"Scarlet": ColorScarlet,
+	"Purple":  ColorPurple,
+}
+
+// ColorNameError is the error of a text which isn't the name of a Color.
+type ColorNameError struct {
+	Text string
+}
+
+func (e *ColorNameError) Error() string {
+	return "invalid Color " + strconv.Quote(e.Text)
+}
+
+// ParseColor returns the value named s, as returned by the String method,
+// ignoring the case if no name matches exactly.
+func ParseColor(s string) (Color, error) {
+	if i, ok := _Color_names[s]; ok {
+		return i, nil
+	}
+	for _, i := range _Color_values {
+		if strings.EqualFold(i.String(), s) {
+			return i, nil
+		}
+	}
+	return 0, &ColorNameError{s}
+}
+
+// MarshalText encodes the value as its name, refusing the undeclared values.
+func (i Color) MarshalText() ([]byte, error) {
+	if !i.IsValid() {
+		return nil, &ColorNameError{i.String()}
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText decodes the value from its name.
+func (i *Color) UnmarshalText(b []byte) (err error) {
+	*i, err = ParseColor(string(b))
+	return
+}
+
+// MarshalJSON encodes the value as a string of its name.
+func (i Color) MarshalJSON() ([]byte, error) {
+	b, err := i.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(string(b))
+}
+
+// UnmarshalJSON decodes the value from a string of its name.
+func (i *Color) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("invalid Color, %w", err)
+	}
+	return i.UnmarshalText([]byte(s))
+}
@@ -0,0 +1,13 @@
+package test
+
+// Color is a color.
+//
+//bitflags:mode enum
+type Color uint8
+
+const (
+	Red Color = iota + 1
+	Green
+	Blue
+	Purple Color = 10
+)
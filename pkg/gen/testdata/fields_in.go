@@ -0,0 +1,18 @@
+package test
+
+// Mode is a file mode.
+//
+//bitflags:field Priority bits=4..6
+//bitflags:field Group bits=8..15 type=uint16
+type Mode uint16
+
+const (
+	Read Mode = 1 << iota
+	Write
+	Exec
+	_
+	_
+	_
+	_
+	Sticky
+)
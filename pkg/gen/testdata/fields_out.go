@@ -0,0 +1,295 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Read-1]
+	_ = x[Write-2]
+	_ = x[Exec-4]
+	_ = x[Sticky-128]
+}
+
+const (
+	_Mode_name_0 = "ReadWrite"
+	_Mode_name_1 = "Exec"
+	_Mode_name_2 = "Sticky"
+)
+
+var (
+	_Mode_index_0 = [...]uint{0, 4, 9}
+)
+
+func (i Mode) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Mode_name_0[_Mode_index_0[i]:_Mode_index_0[i+1]]
+	case i == 4:
+		return _Mode_name_1
+	case i == 128:
+		return _Mode_name_2
+	default:
+		return "Mode(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Mode) Contains(f Mode) bool { return (i & f) == f }
+
+func (i Mode) Read() bool { return i.Contains(Read) }
+
+func (i Mode) Write() bool { return i.Contains(Write) }
+
+func (i Mode) Exec() bool { return i.Contains(Exec) }
+
+func (i Mode) Sticky() bool { return i.Contains(Sticky) }
+
+func (i Mode) String() string {
+	var b strings.Builder
+
+	if i.Read() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Read")
+	}
+
+	if i.Write() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Write")
+	}
+
+	if i.Exec() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Exec")
+	}
+
+	if i.Sticky() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Sticky")
+	}
+
+	if v := i.Priority(); v != 0 {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Priority=")
+		b.WriteString(strconv.FormatUint(uint64(v), 10))
+	}
+
+	if v := i.Group(); v != 0 {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Group=")
+		b.WriteString(strconv.FormatUint(uint64(v), 10))
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Mode) Names() []string {
+	var names []string
+	if i.Read() {
+		names = append(names, "Read")
+	}
+	if i.Write() {
+		names = append(names, "Write")
+	}
+	if i.Exec() {
+		names = append(names, "Exec")
+	}
+	if i.Sticky() {
+		names = append(names, "Sticky")
+	}
+	if v := i.Priority(); v != 0 {
+		names = append(names, "Priority="+strconv.FormatUint(uint64(v), 10))
+	}
+	if v := i.Group(); v != 0 {
+		names = append(names, "Group="+strconv.FormatUint(uint64(v), 10))
+	}
+	return names
+}
+
+// LookupMode returns the flag of the name, as printed by the String method.
+func LookupMode(name string) (Mode, bool) {
+	switch name {
+	case "Read":
+		return Read, true
+	case "Write":
+		return Write, true
+	case "Exec":
+		return Exec, true
+	case "Sticky":
+		return Sticky, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Read.
+func (i Mode) Description() string {
+	return ""
+}
+
+// ModeHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func ModeHelp() [][2]string {
+	return [][2]string{
+		{"Read", ""},
+		{"Write", ""},
+		{"Exec", ""},
+		{"Sticky", ""},
+	}
+}
+
+// ModeFieldError is the error of a value overflowing a field of a Mode.
+type ModeFieldError struct {
+	Field string
+	Value uint64
+	Bits  int // The number of bits of the field.
+}
+
+func (e *ModeFieldError) Error() string {
+	return "value " + strconv.FormatUint(e.Value, 10) + " overflows the " + strconv.Itoa(e.Bits) + " bits of the Mode field " + e.Field
+}
+
+// Priority returns the value of the Priority field, in the bits 4..6.
+func (i Mode) Priority() uint8 { return uint8((i & 0x70) >> 4) }
+
+// SetPriority returns the flags with the Priority field set to v,
+// or a *ModeFieldError if v overflows the 3 bits of the field.
+func (i Mode) SetPriority(v uint8) (Mode, error) {
+	if uint64(v) > 7 {
+		return i, &ModeFieldError{"Priority", uint64(v), 3}
+	}
+	return i&^0x70 | Mode(v)<<4, nil
+}
+
+// Group returns the value of the Group field, in the bits 8..15.
+func (i Mode) Group() uint16 { return uint16((i & 0xff00) >> 8) }
+
+// SetGroup returns the flags with the Group field set to v,
+// or a *ModeFieldError if v overflows the 8 bits of the field.
+func (i Mode) SetGroup(v uint16) (Mode, error) {
+	if uint64(v) > 255 {
+		return i, &ModeFieldError{"Group", uint64(v), 8}
+	}
+	return i&^0xff00 | Mode(v)<<8, nil
+}
+
+var _Mode_flags = map[string]Mode{
+	"Read":   Read,
+	"Write":  Write,
+	"Exec":   Exec,
+	"Sticky": Sticky,
+}
+
+// _Mode_known is the bits of the constants and fields, the others being encoded in hexadecimal, e.g. "0x80".
+const _Mode_known = Read | Write | Exec | Sticky | 0x70 | 0xff00
+
+// _ModeUnknown returns the hexadecimal value of the bits of no constant nor field, if any.
+func _ModeUnknown(i Mode) (string, bool) {
+	if rest := i &^ _Mode_known; rest != 0 {
+		return "0x" + strconv.FormatUint(uint64(rest), 16), true
+	}
+	return "", false
+}
+
+// _ModeFlag returns the flag of the name,
+// or the bits of a Field=value with the mask of the field, set once by the callers,
+// or the undeclared bits of a hexadecimal value.
+func _ModeFlag(name string) (Mode, Mode, error) {
+	if f, ok := _Mode_flags[name]; ok {
+		return f, 0, nil
+	}
+	if field, value, ok := strings.Cut(name, "="); ok {
+		v, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid Mode field %q, %w", name, err)
+		}
+		switch field {
+		case "Priority":
+			if v > 7 {
+				return 0, 0, &ModeFieldError{field, v, 3}
+			}
+			return Mode(v) << 4, 0x70, nil
+		case "Group":
+			if v > 255 {
+				return 0, 0, &ModeFieldError{field, v, 8}
+			}
+			return Mode(v) << 8, 0xff00, nil
+		}
+		return 0, 0, fmt.Errorf("invalid Mode field %q", name)
+	}
+	if strings.HasPrefix(name, "0x") {
+		if v, err := strconv.ParseUint(name[2:], 16, 64); err == nil && v != 0 && uint64(uint16(v)) == v {
+			if f := Mode(v); f&_Mode_known == 0 {
+				return f, 0, nil
+			}
+		}
+	}
+	return 0, 0, fmt.Errorf("invalid Mode flag %q", name)
+}
+
+// _ModeParse returns the flags of the names, failing on a field set twice.
+func _ModeParse(names []string) (Mode, error) {
+	var i, fields Mode
+	for _, name := range names {
+		f, mask, err := _ModeFlag(name)
+		if err != nil {
+			return 0, err
+		}
+		if fields&mask != 0 {
+			return 0, fmt.Errorf("repeated Mode field %q", name)
+		}
+		fields |= mask
+		i |= f
+	}
+	return i, nil
+}
+
+// ParseMode returns the flags named in s, as formatted by the String method, e.g. "Read|Write".
+func ParseMode(s string) (Mode, error) {
+	var i Mode
+	if s == "" {
+		return i, nil
+	}
+	return _ModeParse(strings.Split(s, "|"))
+}
+
+// MarshalJSON encodes the flags as a string, as formatted by the String method,
+// followed by the hexadecimal value of the undeclared bits, if any.
+func (i Mode) MarshalJSON() ([]byte, error) {
+	s := i.String()
+	if rest, ok := _ModeUnknown(i); ok {
+		if s != "" {
+			s += "|"
+		}
+		s += rest
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON decodes the flags from a string, as formatted by the String method.
+func (i *Mode) UnmarshalJSON(b []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(b, &s); err != nil {
+		return
+	}
+	*i, err = ParseMode(s)
+	return
+}
@@ -0,0 +1,208 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Placebo-1]
+	_ = x[Aspirin-2]
+	_ = x[Ibuprofen-4]
+	_ = x[Paracetamol-8]
+}
+
+const (
+	_Pill_name_0 = "PlaceboAspirin"
+	_Pill_name_1 = "Ibuprofen"
+	_Pill_name_2 = "Paracetamol"
+)
+
+var (
+	_Pill_index_0 = [...]uint{0, 7, 14}
+)
+
+func (i Pill) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
+	case i == 4:
+		return _Pill_name_1
+	case i == 8:
+		return _Pill_name_2
+	default:
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Pill) Contains(f Pill) bool { return (i & f) == f }
+
+func (i Pill) Placebo() bool { return i.Contains(Placebo) }
+
+func (i Pill) Aspirin() bool { return i.Contains(Aspirin) }
+
+func (i Pill) Ibuprofen() bool { return i.Contains(Ibuprofen) }
+
+func (i Pill) Paracetamol() bool { return i.Contains(Paracetamol) }
+
+func (i Pill) String() string {
+	var b strings.Builder
+
+	if i.Placebo() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Placebo")
+	}
+
+	if i.Aspirin() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Aspirin")
+	}
+
+	if i.Ibuprofen() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Ibuprofen")
+	}
+
+	if i.Paracetamol() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Aspirin() {
+		names = append(names, "Aspirin")
+	}
+	if i.Ibuprofen() {
+		names = append(names, "Ibuprofen")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Aspirin":
+		return Aspirin, true
+	case "Ibuprofen":
+		return Ibuprofen, true
+	case "Paracetamol":
+		return Paracetamol, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Aspirin", ""},
+		{"Ibuprofen", ""},
+		{"Paracetamol", ""},
+	}
+}
+
+var _Pill_flags = map[string]Pill{
+	"Placebo":     Placebo,
+	"Aspirin":     Aspirin,
+	"Ibuprofen":   Ibuprofen,
+	"Paracetamol": Paracetamol,
+}
+
+// _Pill_known is the bits of the constants, the others being encoded in hexadecimal, e.g. "0x80".
+const _Pill_known = Placebo | Aspirin | Ibuprofen | Paracetamol
+
+// _PillUnknown returns the hexadecimal value of the bits of no constant, if any.
+func _PillUnknown(i Pill) (string, bool) {
+	if rest := i &^ _Pill_known; rest != 0 {
+		return "0x" + strconv.FormatUint(uint64(uint(rest)), 16), true
+	}
+	return "", false
+}
+
+// _PillFlag returns the flag of the name,
+// or the undeclared bits of a hexadecimal value.
+func _PillFlag(name string) (Pill, error) {
+	if f, ok := _Pill_flags[name]; ok {
+		return f, nil
+	}
+	if strings.HasPrefix(name, "0x") {
+		if v, err := strconv.ParseUint(name[2:], 16, 64); err == nil && v != 0 && uint64(uint(v)) == v {
+			if f := Pill(uint(v)); f&_Pill_known == 0 {
+				return f, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid Pill flag %q", name)
+}
+
+// ParsePill returns the flags named in s, as formatted by the String method, e.g. "Placebo|Aspirin".
+func ParsePill(s string) (Pill, error) {
+	var i Pill
+	if s == "" {
+		return i, nil
+	}
+	for _, name := range strings.Split(s, "|") {
+		f, err := _PillFlag(name)
+		if err != nil {
+			return 0, err
+		}
+		i |= f
+	}
+	return i, nil
+}
+
+// MarshalJSON encodes the flags as a string, as formatted by the String method,
+// followed by the hexadecimal value of the undeclared bits, if any.
+func (i Pill) MarshalJSON() ([]byte, error) {
+	s := i.String()
+	if rest, ok := _PillUnknown(i); ok {
+		if s != "" {
+			s += "|"
+		}
+		s += rest
+	}
+	return json.Marshal(s)
+}
+
+// UnmarshalJSON decodes the flags from a string, as formatted by the String method.
+func (i *Pill) UnmarshalJSON(b []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(b, &s); err != nil {
+		return
+	}
+	*i, err = ParsePill(s)
+	return
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Placebo-1]
+	_ = x[Aspirin-2]
+	_ = x[Ibuprofen-4]
+	_ = x[Paracetamol-8]
+}
+
+const (
+	_Pill_name_0 = "PlaceboAspirin"
+	_Pill_name_1 = "Ibuprofen"
+	_Pill_name_2 = "Paracetamol"
+)
+
+var (
+	_Pill_index_0 = [...]uint{0, 7, 14}
+)
+
+func (i Pill) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
+	case i == 4:
+		return _Pill_name_1
+	case i == 8:
+		return _Pill_name_2
+	default:
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Pill) Contains(f Pill) bool { return (i & f) == f }
+
+func (i Pill) Placebo() bool { return i.Contains(Placebo) }
+
+func (i Pill) Aspirin() bool { return i.Contains(Aspirin) }
+
+func (i Pill) Ibuprofen() bool { return i.Contains(Ibuprofen) }
+
+func (i Pill) Paracetamol() bool { return i.Contains(Paracetamol) }
+
+func (i Pill) String() string {
+	var b strings.Builder
+
+	if i.Placebo() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Placebo")
+	}
+
+	if i.Aspirin() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Aspirin")
+	}
+
+	if i.Ibuprofen() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Ibuprofen")
+	}
+
+	if i.Paracetamol() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Aspirin() {
+		names = append(names, "Aspirin")
+	}
+	if i.Ibuprofen() {
+		names = append(names, "Ibuprofen")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Aspirin":
+		return Aspirin, true
+	case "Ibuprofen":
+		return Ibuprofen, true
+	case "Paracetamol":
+		return Paracetamol, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Aspirin", ""},
+		{"Ibuprofen", ""},
+		{"Paracetamol", ""},
+	}
+}
+
+// pillNames lists the signed flags of test.Pill.
+var pillNames = map[Pill]string{
+	Placebo:     "placebo",
+	Aspirin:     "aspirin",
+	Ibuprofen:   "ibuprofen",
+	Paracetamol: "paracetamol",
+}
@@ -28,6 +28,9 @@ var (
 
 func (i Pill) Name() string {
 	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
 	case i == 4:
 		return _Pill_name_1
 	case i == 8:
@@ -80,3 +83,51 @@ func (i Pill) String() string {
 
 	return b.String()
 }
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Aspirin() {
+		names = append(names, "Aspirin")
+	}
+	if i.Ibuprofen() {
+		names = append(names, "Ibuprofen")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Aspirin":
+		return Aspirin, true
+	case "Ibuprofen":
+		return Ibuprofen, true
+	case "Paracetamol":
+		return Paracetamol, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Aspirin", ""},
+		{"Ibuprofen", ""},
+		{"Paracetamol", ""},
+	}
+}
@@ -0,0 +1,22 @@
+
+import enum
+
+
+class Pill(enum.IntFlag):
+    Placebo = 1
+    Aspirin = 2
+    Ibuprofen = 4
+    Paracetamol = 8
+    Acetaminophen = Paracetamol
+
+    def __str__(self):
+        """Returns the names of the flags set joined by "|", like the Go String method."""
+        return "|".join(name for name, flag in _Pill_flags if self & flag == flag)
+
+
+_Pill_flags = (
+    ("Placebo", Pill.Placebo),
+    ("Aspirin", Pill.Aspirin),
+    ("Ibuprofen", Pill.Ibuprofen),
+    ("Paracetamol", Pill.Paracetamol),
+)
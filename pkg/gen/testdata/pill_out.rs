@@ -0,0 +1,40 @@
+
+use bitflags::bitflags;
+
+bitflags! {
+    #[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord, Hash)]
+    pub struct Pill: i64 {
+        #[allow(non_upper_case_globals)]
+        const Placebo = 1;
+        #[allow(non_upper_case_globals)]
+        const Aspirin = 2;
+        #[allow(non_upper_case_globals)]
+        const Ibuprofen = 4;
+        #[allow(non_upper_case_globals)]
+        const Paracetamol = 8;
+        #[allow(non_upper_case_globals)]
+        const Acetaminophen = Self::Paracetamol.bits();
+    }
+}
+
+impl ::core::fmt::Display for Pill {
+    /// Writes the names of the flags set joined by "|", like the Go String method.
+    fn fmt(&self, f: &mut ::core::fmt::Formatter<'_>) -> ::core::fmt::Result {
+        let mut first = true;
+        for (name, flag) in [
+            ("Placebo", Self::Placebo),
+            ("Aspirin", Self::Aspirin),
+            ("Ibuprofen", Self::Ibuprofen),
+            ("Paracetamol", Self::Paracetamol),
+        ] {
+            if self.contains(flag) {
+                if !first {
+                    f.write_str("|")?;
+                }
+                f.write_str(name)?;
+                first = false;
+            }
+        }
+        Ok(())
+    }
+}
@@ -0,0 +1,49 @@
+
+export type Pill = number;
+
+export const Pill = Object.freeze({
+  Placebo: 1,
+  Aspirin: 2,
+  Ibuprofen: 4,
+  Paracetamol: 8,
+  Acetaminophen: 8,
+} as const);
+
+const _Pill_flags: readonly (readonly [string, Pill])[] = [
+  ["Placebo", 1],
+  ["Aspirin", 2],
+  ["Ibuprofen", 4],
+  ["Paracetamol", 8],
+];
+
+/** hasPill reports whether all the flags of f are set in v, like the Go Contains method. */
+export function hasPill(v: Pill, f: Pill): boolean {
+  return (v & f) === f;
+}
+
+/** formatPill returns the names of the flags set in v joined by "|", like the Go String method. */
+export function formatPill(v: Pill): string {
+  const names: string[] = [];
+  for (const [name, f] of _Pill_flags) {
+    if (hasPill(v, f)) {
+      names.push(name);
+    }
+  }
+  return names.join("|");
+}
+
+/** parsePill returns the flags named in s, as formatted by formatPill. */
+export function parsePill(s: string): Pill {
+  let v: Pill = 0;
+  if (s === "") {
+    return v;
+  }
+  for (const name of s.split("|")) {
+    const flag = _Pill_flags.find(([n]) => n === name);
+    if (flag === undefined) {
+      throw new Error(`invalid Pill flag "${name}"`);
+    }
+    v |= flag[1];
+  }
+  return v;
+}
@@ -0,0 +1,17 @@
+package test
+
+// Pill is a pill.
+//
+//bitflags:exclusive Placebo,Aspirin,Ibuprofen
+//bitflags:exclusive Placebo,Paracetamol
+//bitflags:requires Paracetamol=>Ibuprofen
+//bitflags:requires Ibuprofen=>Codeine
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << iota
+	Aspirin
+	Ibuprofen
+	Paracetamol
+	Codeine
+)
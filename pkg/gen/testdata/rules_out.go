@@ -0,0 +1,204 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Placebo-1]
+	_ = x[Aspirin-2]
+	_ = x[Ibuprofen-4]
+	_ = x[Paracetamol-8]
+	_ = x[Codeine-16]
+}
+
+const (
+	_Pill_name_0 = "PlaceboAspirin"
+	_Pill_name_1 = "Ibuprofen"
+	_Pill_name_2 = "Paracetamol"
+	_Pill_name_3 = "Codeine"
+)
+
+var (
+	_Pill_index_0 = [...]uint{0, 7, 14}
+)
+
+func (i Pill) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
+	case i == 4:
+		return _Pill_name_1
+	case i == 8:
+		return _Pill_name_2
+	case i == 16:
+		return _Pill_name_3
+	default:
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Pill) Contains(f Pill) bool { return (i & f) == f }
+
+func (i Pill) Placebo() bool { return i.Contains(Placebo) }
+
+func (i Pill) Aspirin() bool { return i.Contains(Aspirin) }
+
+func (i Pill) Ibuprofen() bool { return i.Contains(Ibuprofen) }
+
+func (i Pill) Paracetamol() bool { return i.Contains(Paracetamol) }
+
+func (i Pill) Codeine() bool { return i.Contains(Codeine) }
+
+func (i Pill) String() string {
+	var b strings.Builder
+
+	if i.Placebo() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Placebo")
+	}
+
+	if i.Aspirin() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Aspirin")
+	}
+
+	if i.Ibuprofen() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Ibuprofen")
+	}
+
+	if i.Paracetamol() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	if i.Codeine() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Codeine")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Aspirin() {
+		names = append(names, "Aspirin")
+	}
+	if i.Ibuprofen() {
+		names = append(names, "Ibuprofen")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	if i.Codeine() {
+		names = append(names, "Codeine")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Aspirin":
+		return Aspirin, true
+	case "Ibuprofen":
+		return Ibuprofen, true
+	case "Paracetamol":
+		return Paracetamol, true
+	case "Codeine":
+		return Codeine, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Aspirin", ""},
+		{"Ibuprofen", ""},
+		{"Paracetamol", ""},
+		{"Codeine", ""},
+	}
+}
+
+// PillRuleError is the error of flags violating the //bitflags:exclusive and //bitflags:requires rules.
+type PillRuleError struct {
+	Flags Pill
+	Rules []string // The violated rules, as in the directives, e.g. "requires A=>B".
+}
+
+func (e *PillRuleError) Error() string {
+	return "invalid Pill " + e.Flags.String() + ", violates " + strings.Join(e.Rules, "; ")
+}
+
+// Validate returns a *PillRuleError listing the rules violated by the flags, or nil.
+func (i Pill) Validate() error {
+	var rules []string
+	if _Pill_count(i, Placebo, Aspirin, Ibuprofen) > 1 {
+		rules = append(rules, "exclusive Placebo,Aspirin,Ibuprofen")
+	}
+	if _Pill_count(i, Placebo, Paracetamol) > 1 {
+		rules = append(rules, "exclusive Placebo,Paracetamol")
+	}
+	if i.Contains(Paracetamol) && !i.Contains(Ibuprofen) {
+		rules = append(rules, "requires Paracetamol=>Ibuprofen")
+	}
+	if i.Contains(Ibuprofen) && !i.Contains(Codeine) {
+		rules = append(rules, "requires Ibuprofen=>Codeine")
+	}
+	if rules != nil {
+		return &PillRuleError{i, rules}
+	}
+	return nil
+}
+
+// Normalize returns the flags with the flags they require, directly or not, added.
+func (i Pill) Normalize() Pill {
+	n := i
+	if i.Contains(Ibuprofen) {
+		n |= Codeine
+	}
+	if i.Contains(Paracetamol) {
+		n |= Codeine | Ibuprofen
+	}
+	return n
+}
+
+func _Pill_count(i Pill, flags ...Pill) (n int) {
+	for _, f := range flags {
+		if i.Contains(f) {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,176 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Pill is a set of pills.
+type Pill uint8
+
+const (
+	Placebo Pill = 1 << 0
+	// Aspirin relieves pain.
+	Aspirin     Pill = 1 << 1
+	Paracetamol Pill = 4
+	// Deprecated: use Paracetamol
+	Ibuprofen     Pill = 1 << 3
+	Acetaminophen      = Paracetamol
+)
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+
+	_ = x[Placebo-1]
+	_ = x[Aspirin-2]
+	_ = x[Paracetamol-4]
+	_ = x[Ibuprofen-8]
+}
+
+const (
+	_Pill_name_0 = "PlaceboAspirin"
+	_Pill_name_1 = "Paracetamol"
+	_Pill_name_2 = "Ibuprofen"
+)
+
+var (
+	_Pill_index_0 = [...]uint{0, 7, 14}
+)
+
+func (i Pill) Name() string {
+	switch {
+	case 1 <= i && i <= 2:
+		i -= 1
+		return _Pill_name_0[_Pill_index_0[i]:_Pill_index_0[i+1]]
+	case i == 4:
+		return _Pill_name_1
+	case i == 8:
+		return _Pill_name_2
+	default:
+		return "Pill(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+}
+
+func (i Pill) Contains(f Pill) bool { return (i & f) == f }
+
+func (i Pill) Placebo() bool { return i.Contains(Placebo) }
+
+func (i Pill) Aspirin() bool { return i.Contains(Aspirin) }
+
+func (i Pill) Paracetamol() bool { return i.Contains(Paracetamol) }
+
+func (i Pill) Ibuprofen() bool { return i.Contains(Ibuprofen) }
+
+func (i Pill) String() string {
+	var b strings.Builder
+
+	if i.Placebo() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Placebo")
+	}
+
+	if i.Aspirin() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Aspirin")
+	}
+
+	if i.Paracetamol() {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	if i.Contains(Ibuprofen) && !i.Contains(Paracetamol) {
+		if b.Len() > 0 {
+			b.WriteByte('|')
+		}
+		b.WriteString("Paracetamol")
+	}
+
+	return b.String()
+}
+
+// Names returns the names of the flags set, as joined by the String method.
+func (i Pill) Names() []string {
+	var names []string
+	if i.Placebo() {
+		names = append(names, "Placebo")
+	}
+	if i.Aspirin() {
+		names = append(names, "Aspirin")
+	}
+	if i.Paracetamol() {
+		names = append(names, "Paracetamol")
+	}
+	if i.Contains(Ibuprofen) && !i.Contains(Paracetamol) {
+		names = append(names, "Paracetamol")
+	}
+	return names
+}
+
+// LookupPill returns the flag of the name, as printed by the String method,
+// or the replacement of a deprecated one.
+func LookupPill(name string) (Pill, bool) {
+	switch name {
+	case "Placebo":
+		return Placebo, true
+	case "Aspirin":
+		return Aspirin, true
+	case "Paracetamol":
+		return Paracetamol, true
+	case "Ibuprofen":
+		if PillDeprecationHook != nil {
+			PillDeprecationHook(name, "use Paracetamol")
+		}
+		return Paracetamol, true
+	}
+	return 0, false
+}
+
+// Description returns the doc comment of a single flag, e.g. Placebo.
+func (i Pill) Description() string {
+	switch i {
+	case Aspirin:
+		return "Aspirin relieves pain."
+	case Ibuprofen:
+		return "Deprecated: use Paracetamol"
+	}
+	return ""
+}
+
+// PillHelp returns the names of the flags, as printed by the String method, paired with their descriptions.
+func PillHelp() [][2]string {
+	return [][2]string{
+		{"Placebo", ""},
+		{"Aspirin", "Aspirin relieves pain."},
+		{"Paracetamol", ""},
+	}
+}
+
+// PillDeprecationHook is called, if not nil, with the name and the deprecation notice
+// of the deprecated flags looked up by LookupPill.
+var PillDeprecationHook func(name, notice string)
+
+var _Pill_deprecated = map[string]struct {
+	flag, replacement Pill
+	notice            string
+}{
+	"Ibuprofen": {Ibuprofen, Paracetamol, "use Paracetamol"},
+}
+
+// IsDeprecated returns the deprecation notice of a single deprecated flag.
+func (i Pill) IsDeprecated() (string, bool) {
+	for _, d := range _Pill_deprecated {
+		if d.flag == i {
+			return d.notice, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,112 @@
+package gen
+
+import (
+	"io"
+	"math"
+	"strings"
+)
+
+// TypeScriptBackend generates a TypeScript module of the flag sets, with the values,
+// and the has, format and parse functions matching the Go methods.
+type TypeScriptBackend struct{}
+
+func (TypeScriptBackend) Header(w io.Writer, pkg *Package) error {
+	return langTemplates.ExecuteTemplate(w, "header.ts.tmpl", map[string]interface{}{
+		"CmdLine": cmdLine(),
+		"Package": pkg,
+	})
+}
+
+func (TypeScriptBackend) Generate(w io.Writer, fs *FlagSet) error {
+	type value struct {
+		Value
+		Lit string // The TypeScript literal of the value.
+		// ReplacementLit is the literal of the replacement of a deprecated value, or of the value.
+		ReplacementLit string
+	}
+
+	// The values are numbers, as decoded from JSON, unless they aren't safe integers.
+	bigint := false
+	// The bitwise operators of numbers are limited to 32 bits signed integers.
+	native := true
+
+	for _, v := range fs.Values {
+		if fs.Signed && int64(v.Value) < 0 {
+			native = false
+			bigint = bigint || int64(v.Value) < -(1<<53-1)
+		} else {
+			native = native && v.Value <= math.MaxInt32
+			bigint = bigint || v.Value > 1<<53-1
+		}
+	}
+
+	lit := func(v Value) string {
+		if bigint {
+			return v.Str + "n"
+		}
+		return v.Str
+	}
+
+	lits := make(map[string]string, len(fs.Values)) // original name => literal
+	for _, v := range fs.Values {
+		lits[v.OriginalName] = lit(v)
+	}
+
+	values := make([]value, len(fs.Values))
+	for i, v := range fs.Values {
+		values[i] = value{v, lits[v.OriginalName], lits[v.OriginalName]}
+		if v.Replacement != "" {
+			values[i].ReplacementLit = lits[v.Replacement]
+		}
+	}
+
+	aliases := make([]value, 0, len(fs.Aliases))
+	for _, a := range fs.Aliases {
+		for _, v := range fs.Values {
+			if v.OriginalName == a.Target {
+				aliases = append(aliases, value{Value{OriginalName: a.Name, Name: a.Name, Doc: a.Doc}, lit(v), ""})
+				break
+			}
+		}
+	}
+
+	return langTemplates.ExecuteTemplate(w, "flags.ts.tmpl", struct {
+		*FlagSet
+		Values   []value
+		Aliases  []value
+		Mappings []langMapping
+		BigInt   bool
+		Native   bool
+	}{fs, values, aliases, langMappings(fs, func(name string) string { return lits[name] }), bigint, native && !bigint})
+}
+
+// Format keeps the TypeScript source as is.
+func (TypeScriptBackend) Format(src []byte) ([]byte, error) {
+	return src, nil
+}
+
+// jsdoc formats the text as a /** */ comment, with a "Deprecated:" paragraph as a @deprecated tag.
+func jsdoc(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Deprecated: ") {
+			lines[i] = "@deprecated " + strings.TrimPrefix(line, "Deprecated: ")
+		}
+	}
+
+	if len(lines) == 1 {
+		return "/** " + lines[0] + " */\n"
+	}
+
+	var b strings.Builder
+	b.WriteString("/**\n")
+	for _, line := range lines {
+		b.WriteString(" *")
+		if line != "" {
+			b.WriteString(" " + line)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString(" */\n")
+	return b.String()
+}
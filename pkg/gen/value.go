@@ -1,5 +1,10 @@
 package gen
 
+import (
+	"go/token"
+	"strings"
+)
+
 // Value represents a declared constant.
 type Value struct {
 	OriginalName string // The name of the constant.
@@ -12,12 +17,45 @@ type Value struct {
 	Value  uint64 // Will be converted to int64 when needed.
 	Signed bool   // Whether the constant is a signed type.
 	Str    string // The string representation given by the "go/constant" package.
+	Doc    string // The doc comment of the constant.
+	// Comment is the trailing line comment of the constant, without the directives.
+	Comment string
+	Expr    string // The expression declaring the constant, when declared by the generator.
+	// Retired marks a constant that deliberately reuses a bit retired in the lock file.
+	Retired    bool
+	Pos        token.Position // Position of the constant declaration.
+	Directives []Directive    // The //bitflags: directives of the constant.
+	Attrs      []Attr         // The attributes of the //bitflags:attr directives.
+	// Deprecated is the notice of the "Deprecated: " paragraph of the doc, or trailing, comment,
+	// e.g. "use Paracetamol"; deprecated values are parsed, but not printed.
+	Deprecated string
+	// Replacement is the original name of the value replacing a deprecated one, if any.
+	Replacement string
 }
 
 func (v *Value) String() string {
 	return v.Str
 }
 
+// Description returns the doc comment, or else the trailing comment unless it is the name, on a single line.
+func (v Value) Description() string {
+	text := v.Doc
+	if text == "" && v.Comment != v.Name {
+		text = v.Comment
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// deprecation returns the notice of the "Deprecated: " paragraph of the comment, on a single line.
+func deprecation(comment string) string {
+	for _, para := range strings.Split(comment, "\n\n") {
+		if strings.HasPrefix(para, "Deprecated: ") {
+			return strings.Join(strings.Fields(strings.TrimPrefix(para, "Deprecated: ")), " ")
+		}
+	}
+	return ""
+}
+
 // byValue lets us sort the constants into increasing order.
 // We take care in the Less method to sort in signed or unsigned order,
 // as appropriate.
@@ -0,0 +1,339 @@
+package bitflags
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is the error of the modifications of a closed Watched.
+var ErrClosed = errors.New("bitflags: watched flags closed")
+
+// Change is a change of watched flags.
+type Change[T Bits] struct {
+	Old, New       T
+	Added, Removed T // The flags added and removed, within the mask of the subscription.
+}
+
+// Policy is what a change does to a subscriber whose buffer is full.
+type Policy int
+
+const (
+	Block Policy = iota // The change waits for the subscriber, blocking the writer.
+	Drop                // The change is dropped for the subscriber, and counted by Dropped.
+)
+
+// SubscribeOption is an option of the subscriptions.
+type SubscribeOption func(*subscribeOptions)
+
+type subscribeOptions struct {
+	buffer int
+	policy Policy
+}
+
+// WithBuffer sets the number of changes buffered for the subscriber, 16 by default.
+// It panics if n is negative.
+func WithBuffer(n int) SubscribeOption {
+	if n < 0 {
+		panic("bitflags: negative buffer of changes " + strconv.Itoa(n))
+	}
+	return func(o *subscribeOptions) { o.buffer = n }
+}
+
+// WithPolicy sets what a change does when the buffer of the subscriber is full, Block by default.
+func WithPolicy(p Policy) SubscribeOption {
+	return func(o *subscribeOptions) { o.policy = p }
+}
+
+// Watched holds flags, broadcasting their changes to the subscribers.
+//
+// It is safe for concurrent use. The changes are delivered to each subscriber
+// in the order of the modifications.
+type Watched[T Bits] struct {
+	mu     sync.Mutex
+	value  T
+	subs   map[*Subscription[T]]struct{}
+	closed bool
+}
+
+// Subscription is a subscription to the changes of a Watched.
+type Subscription[T Bits] struct {
+	dropped uint64 // First, to be 64-bit aligned for the atomic operations.
+
+	// C receives the changes, until the subscription, or the Watched, is closed.
+	// It is nil for the subscriptions with a callback.
+	C <-chan Change[T]
+
+	w      *Watched[T]
+	mask   T
+	policy Policy
+	ch     chan Change[T]
+	done   chan struct{}
+	once   sync.Once
+
+	// The changes are numbered with w.mu held, and sent in turn after unlocking it,
+	// the channel being closed once the last numbered change is sent.
+	mu         sync.Mutex
+	turn       sync.Cond
+	next, sent uint64
+	closing    bool
+
+	// The goroutine calling the callback, and whether it is calling it, to skip its own changes.
+	goroutine uint64
+	calling   uint32
+}
+
+// delivery is a change numbered for a subscriber.
+type delivery[T Bits] struct {
+	s   *Subscription[T]
+	c   Change[T]
+	seq uint64
+}
+
+// NewWatched returns watched flags holding the value.
+func NewWatched[T Bits](v T) *Watched[T] {
+	return &Watched[T]{value: v, subs: make(map[*Subscription[T]]struct{})}
+}
+
+// Load returns the flags.
+func (w *Watched[T]) Load() T {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.value
+}
+
+// Set replaces the flags.
+func (w *Watched[T]) Set(v T) error {
+	return w.update(func(T) T { return v })
+}
+
+// Insert sets the flags of the mask.
+func (w *Watched[T]) Insert(mask T) error {
+	return w.update(func(v T) T { return v | mask })
+}
+
+// Remove clears the flags of the mask.
+func (w *Watched[T]) Remove(mask T) error {
+	return w.update(func(v T) T { return v &^ mask })
+}
+
+// update modifies the flags, and broadcasts the change to the subscribers of the changed flags.
+//
+// The change is sent after unlocking w.mu, for the subscribers to use the Watched meanwhile,
+// but after the previous changes to each subscriber, keeping their order.
+func (w *Watched[T]) update(f func(T) T) error {
+	w.mu.Lock()
+
+	if w.closed {
+		w.mu.Unlock()
+		return ErrClosed
+	}
+
+	old := w.value
+	w.value = f(old)
+	added, removed := w.value&^old, old&^w.value
+
+	var ds []delivery[T]
+	var self uint64 // The calling goroutine, once needed.
+	for s := range w.subs {
+		if (added|removed)&s.mask == 0 {
+			continue
+		}
+		if atomic.LoadUint32(&s.calling) != 0 {
+			if self == 0 {
+				self = goroutineID()
+			}
+			if self == s.goroutine {
+				continue // A change made by the callback, which would wait for itself.
+			}
+		}
+		ds = append(ds, delivery[T]{s, Change[T]{old, w.value, added & s.mask, removed & s.mask}, s.number()})
+	}
+	w.mu.Unlock()
+
+	for _, d := range ds {
+		d.s.deliver(d.c, d.seq)
+	}
+	return nil
+}
+
+// Subscribe returns a subscription receiving the changes of the flags of the mask,
+// or of all the flags if the mask is zero, on its channel.
+func (w *Watched[T]) Subscribe(mask T, opts ...SubscribeOption) *Subscription[T] {
+	return w.subscribe(mask, opts)
+}
+
+// Notify returns a subscription calling f with the changes of the flags of the mask,
+// or of all the flags if the mask is zero, from a goroutine of the subscription.
+//
+// The buffer and the policy of the subscription apply to the changes waiting for f.
+// f may load and modify the flags, but isn't called with the changes it makes itself,
+// which would otherwise wait for f to return once the buffer is full.
+func (w *Watched[T]) Notify(mask T, f func(Change[T]), opts ...SubscribeOption) *Subscription[T] {
+	s := w.subscribe(mask, opts)
+	s.C = nil
+
+	go func() {
+		s.goroutine = goroutineID() // Before the first call, published by calling.
+		for c := range s.ch {
+			select {
+			case <-s.done:
+				// Drain the changes buffered before the subscription was closed.
+			default:
+				atomic.StoreUint32(&s.calling, 1)
+				f(c)
+				atomic.StoreUint32(&s.calling, 0)
+			}
+		}
+	}()
+
+	return s
+}
+
+func (w *Watched[T]) subscribe(mask T, opts []SubscribeOption) *Subscription[T] {
+	o := subscribeOptions{buffer: 16}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if mask == 0 {
+		mask = ^mask
+	}
+
+	s := &Subscription[T]{
+		w:      w,
+		mask:   mask,
+		policy: o.policy,
+		ch:     make(chan Change[T], o.buffer),
+		done:   make(chan struct{}),
+	}
+	s.C = s.ch
+	s.turn.L = &s.mu
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		s.once.Do(func() { close(s.done) })
+		close(s.ch)
+	} else {
+		w.subs[s] = struct{}{}
+	}
+	return s
+}
+
+// Close closes the subscriptions, and fails the later modifications with ErrClosed.
+func (w *Watched[T]) Close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+	w.closed = true
+
+	for s := range w.subs {
+		s.once.Do(func() { close(s.done) })
+		s.closeChannel()
+		delete(w.subs, s)
+	}
+}
+
+// number numbers a change to the subscriber, with w.mu held.
+func (s *Subscription[T]) number() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.next++
+	return s.next - 1
+}
+
+// deliver sends the change numbered seq, once the previous changes are sent.
+func (s *Subscription[T]) deliver(c Change[T], seq uint64) {
+	s.mu.Lock()
+	for s.sent != seq {
+		s.turn.Wait()
+	}
+	s.mu.Unlock()
+
+	s.send(c)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sent++
+	s.turn.Broadcast()
+	if s.closing && s.sent == s.next {
+		close(s.ch)
+	}
+}
+
+// closeChannel closes the channel, or lets the last numbered change close it once sent,
+// with w.mu held.
+func (s *Subscription[T]) closeChannel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closing = true
+	if s.sent == s.next {
+		close(s.ch)
+	}
+}
+
+// send sends the change, unless the subscription is closed.
+func (s *Subscription[T]) send(c Change[T]) {
+	select {
+	case <-s.done:
+		return
+	default:
+	}
+
+	if s.policy == Drop {
+		select {
+		case s.ch <- c:
+		default:
+			atomic.AddUint64(&s.dropped, 1)
+		}
+		return
+	}
+
+	select {
+	case s.ch <- c:
+	case <-s.done:
+	}
+}
+
+// Dropped returns the number of changes dropped for the subscriber with the Drop policy.
+func (s *Subscription[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close unsubscribes, closing the channel of the subscription, and releasing the writers
+// blocked by the subscriber. The callback isn't called anymore, except if already running;
+// Close may be called from the callback.
+func (s *Subscription[T]) Close() {
+	s.once.Do(func() { close(s.done) })
+
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+
+	if _, ok := s.w.subs[s]; ok {
+		delete(s.w.subs, s)
+		s.closeChannel()
+	}
+}
+
+// goroutineID returns the id of the calling goroutine, from the header of its stack trace,
+// e.g. "goroutine 18 [running]:".
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
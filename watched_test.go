@@ -0,0 +1,222 @@
+package bitflags
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatched(t *testing.T) {
+	w := NewWatched(placebo)
+	all := w.Subscribe(0)
+	pain := w.Subscribe(ibuprofen | paracetamol)
+
+	if err := w.Insert(aspirin); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Set(ibuprofen); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Remove(ibuprofen); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Remove(ibuprofen); err != nil { // No change.
+		t.Fatal(err)
+	}
+
+	for _, want := range []Change[pill]{
+		{placebo, placebo | aspirin, aspirin, 0},
+		{placebo | aspirin, ibuprofen, ibuprofen, placebo | aspirin},
+		{ibuprofen, 0, 0, ibuprofen},
+	} {
+		if got := <-all.C; got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+	for _, want := range []Change[pill]{
+		{placebo | aspirin, ibuprofen, ibuprofen, 0},
+		{ibuprofen, 0, 0, ibuprofen},
+	} {
+		if got := <-pain.C; got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	}
+
+	w.Close()
+	if _, ok := <-all.C; ok {
+		t.Errorf("expected the channel closed")
+	}
+	if err := w.Insert(aspirin); !errors.Is(err, ErrClosed) {
+		t.Errorf("got %v, want ErrClosed", err)
+	}
+	all.Close() // Closing twice is harmless.
+	if _, ok := <-w.Subscribe(0).C; ok {
+		t.Errorf("expected the channel of a late subscription closed")
+	}
+}
+
+func TestWatchedPolicies(t *testing.T) {
+	w := NewWatched[pill](0)
+
+	dropped := w.Subscribe(0, WithBuffer(1), WithPolicy(Drop))
+	blocked := w.Subscribe(0, WithBuffer(1))
+
+	w.Insert(placebo)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Insert(aspirin) // Blocks on the full buffer of blocked.
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected the writer blocked")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	blocked.Close() // Releases the writer.
+	<-done
+
+	if n := dropped.Dropped(); n != 1 {
+		t.Errorf("got %d dropped changes, want 1", n)
+	}
+}
+
+func TestWatchedNotify(t *testing.T) {
+	w := NewWatched[pill](0)
+
+	var mu sync.Mutex
+	var added pill
+	var sub *Subscription[pill]
+	calls := make(chan struct{}, 201)
+	sub = w.Notify(aspirin|ibuprofen, func(c Change[pill]) {
+		mu.Lock()
+		added |= c.Added
+		mu.Unlock()
+		if c.Added&ibuprofen != 0 {
+			sub.Close() // From the callback.
+		}
+		calls <- struct{}{}
+	})
+
+	var wg sync.WaitGroup
+	for _, f := range []pill{placebo, aspirin, paracetamol} {
+		wg.Add(1)
+		go func(f pill) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				w.Insert(f)
+				w.Remove(f)
+			}
+		}(f)
+	}
+	wg.Wait()
+
+	w.Insert(ibuprofen)
+	for i := 0; i < 201; i++ {
+		<-calls
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if added != aspirin|ibuprofen {
+		t.Errorf("got added %v", added)
+	}
+}
+
+func TestWatchedNotifyReentrant(t *testing.T) {
+	w := NewWatched[pill](0)
+	pain := w.Subscribe(ibuprofen)
+
+	gate := make(chan struct{})
+	calls := make(chan struct{}, 3)
+	sub := w.Notify(aspirin|paracetamol, func(c Change[pill]) {
+		<-gate
+		w.Load() // Once deadlocked with the writer blocked on the full buffer.
+		if c.Added&aspirin != 0 {
+			w.Insert(ibuprofen)
+		}
+		calls <- struct{}{}
+	}, WithBuffer(1))
+	defer sub.Close()
+
+	w.Insert(aspirin)     // Called back, waiting for the gate.
+	w.Insert(paracetamol) // Buffered.
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Remove(paracetamol) // Blocks on the full buffer.
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-calls:
+		case <-time.After(time.Second):
+			t.Fatal("expected the callback called")
+		}
+	}
+	<-done
+
+	if c := <-pain.C; c.Added != ibuprofen {
+		t.Errorf("got %+v, want ibuprofen added", c)
+	}
+	if v := w.Load(); v != aspirin|ibuprofen {
+		t.Errorf("got %v", v)
+	}
+}
+
+func TestWatchedNotifyOwnChanges(t *testing.T) {
+	w := NewWatched[pill](0)
+
+	gate := make(chan struct{})
+	calls := make(chan Change[pill], 3)
+	sub := w.Notify(placebo|aspirin|paracetamol, func(c Change[pill]) {
+		if c.Added == aspirin {
+			<-gate
+			w.Insert(paracetamol) // Once waited for the full buffer, so for itself.
+		}
+		calls <- c
+	}, WithBuffer(1))
+	defer sub.Close()
+
+	w.Insert(aspirin) // Called back, waiting for the gate.
+	w.Insert(placebo) // Buffered.
+	close(gate)
+
+	for _, want := range []Change[pill]{
+		{0, aspirin, aspirin, 0},
+		{aspirin, placebo | aspirin, placebo, 0},
+	} {
+		select {
+		case got := <-calls:
+			if got != want {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("expected the callback called")
+		}
+	}
+
+	if v := w.Load(); v != placebo|aspirin|paracetamol {
+		t.Errorf("got %v", v)
+	}
+	select {
+	case c := <-calls:
+		t.Errorf("got the change %+v of the callback itself", c)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestWithNegativeBuffer(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	WithBuffer(-1)
+}